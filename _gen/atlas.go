@@ -0,0 +1,342 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// pageSameAsBase is the size of a page that is the same as the page of the base atlas.
+const pageSameAsBase = 0xffffffff
+
+const (
+	atlasMagic   = "BMFA"
+	atlasVersion = 3
+)
+
+// atlasHeader describes an atlas.
+type atlasHeader struct {
+	// size is the glyph size in pixels like 12.
+	size int
+
+	// cellWidth and cellHeight are the size of a fullwidth glyph region.
+	cellWidth  int
+	cellHeight int
+
+	// dotY is the baseline position in a glyph region.
+	dotY int
+
+	// runesPerPage is the number of runes in a page. A page is a row of the atlas.
+	runesPerPage int
+
+	eastAsianWide bool
+
+	// capHeight and xHeight are the heights of the ink of 'H' and 'x' above the baseline.
+	capHeight int
+	xHeight   int
+
+	// caretSlopeRun and caretSlopeRise are the slope of the caret.
+	caretSlopeRun  int
+	caretSlopeRise int
+
+	language string
+
+	// sources is the names of the fonts that the glyphs come from.
+	sources []string
+}
+
+// appendAtlasHeader appends the header to bs.
+//
+// The header starts with the magic "BMFA", the version as a 16-bit big endian integer,
+// and the CRC-32 (IEEE) checksum of all the following bytes of the file as a 32-bit big endian integer.
+// The size, the cell width, the cell height, and the baseline position follow them as 8-bit integers.
+// The number of the runes in a page as a 16-bit big endian integer and the flags as an 8-bit integer follow them.
+// The only flag is 1 for East Asian wide.
+// The cap height and the x-height as 8-bit integers follow them.
+// The run and the rise of the caret slope as signed 8-bit integers follow them.
+// The language and the sources follow them as strings that start with their lengths as 8-bit integers.
+// The number of the sources as an 8-bit integer precedes the sources.
+//
+// The checksum is filled by putAtlasChecksum.
+func appendAtlasHeader(bs []byte, h *atlasHeader) []byte {
+	bs = append(bs, atlasMagic...)
+	bs = binary.BigEndian.AppendUint16(bs, atlasVersion)
+	bs = binary.BigEndian.AppendUint32(bs, 0)
+	bs = append(bs, byte(h.size), byte(h.cellWidth), byte(h.cellHeight), byte(h.dotY))
+	bs = binary.BigEndian.AppendUint16(bs, uint16(h.runesPerPage))
+	var flags byte
+	if h.eastAsianWide {
+		flags |= 1
+	}
+	bs = append(bs, flags)
+	bs = append(bs, byte(h.capHeight), byte(h.xHeight), byte(int8(h.caretSlopeRun)), byte(int8(h.caretSlopeRise)))
+	bs = append(bs, byte(len(h.language)))
+	bs = append(bs, h.language...)
+	bs = append(bs, byte(len(h.sources)))
+	for _, s := range h.sources {
+		bs = append(bs, byte(len(s)))
+		bs = append(bs, s...)
+	}
+	return bs
+}
+
+func putAtlasChecksum(bs []byte) {
+	binary.BigEndian.PutUint32(bs[len(atlasMagic)+2:], crc32.ChecksumIEEE(bs[len(atlasMagic)+6:]))
+}
+
+// readAtlasHeader reads the header written by appendAtlasHeader, and returns the rest of bs.
+func readAtlasHeader(bs []byte) (*atlasHeader, []byte, error) {
+	if len(bs) < len(atlasMagic)+6 || string(bs[:len(atlasMagic)]) != atlasMagic {
+		return nil, nil, fmt.Errorf("gen: not an atlas")
+	}
+	if v := binary.BigEndian.Uint16(bs[len(atlasMagic):]); v != atlasVersion {
+		return nil, nil, fmt.Errorf("gen: unsupported atlas version: %d", v)
+	}
+	if binary.BigEndian.Uint32(bs[len(atlasMagic)+2:]) != crc32.ChecksumIEEE(bs[len(atlasMagic)+6:]) {
+		return nil, nil, fmt.Errorf("gen: atlas checksum mismatch")
+	}
+	bs = bs[len(atlasMagic)+6:]
+
+	h := &atlasHeader{
+		size:           int(bs[0]),
+		cellWidth:      int(bs[1]),
+		cellHeight:     int(bs[2]),
+		dotY:           int(bs[3]),
+		runesPerPage:   int(binary.BigEndian.Uint16(bs[4:])),
+		eastAsianWide:  bs[6]&1 != 0,
+		capHeight:      int(bs[7]),
+		xHeight:        int(bs[8]),
+		caretSlopeRun:  int(int8(bs[9])),
+		caretSlopeRise: int(int8(bs[10])),
+	}
+	bs = bs[11:]
+	h.language, bs = string(bs[1:1+bs[0]]), bs[1+bs[0]:]
+	n := int(bs[0])
+	bs = bs[1:]
+	for range n {
+		var s string
+		s, bs = string(bs[1:1+bs[0]]), bs[1+bs[0]:]
+		h.sources = append(h.sources, s)
+	}
+	return h, bs, nil
+}
+
+// writePagedAtlas writes the atlas bits as independently compressed pages.
+// A page is a row of the atlas, which has glyphs for 256 runes.
+//
+// If base is not empty, the pages that are the same as base's are omitted.
+// base must have the same supplementary pages and the same layout.
+//
+// The data starts with the header. See appendAtlasHeader.
+// The length of the base file name as a 16-bit big endian integer and the name follow it.
+// The name is relative to the directory of the atlas, and is empty if there is no base.
+// The number of the supplementary pages and the page numbers as 16-bit big endian integers follow it.
+// The size of the compressed coverage as a 32-bit big endian integer and the coverage as an LZ4 block follow them.
+// The coverage has 256 bits for each row, and a bit is set when the rune has a glyph.
+// The coverage is not shared with the base.
+// The proportional table follows it. See appendProportionalTable.
+// The sizes of the compressed pages follow it as 32-bit big endian integers.
+// An empty page is omitted and its size is 0.
+// A page that is the same as the base's is omitted and its size is 0xffffffff.
+// A page that cannot be compressed is stored as it is, and its size is the same as the uncompressed size.
+// The compressed pages as LZ4 blocks follow the sizes.
+func writePagedAtlas(path string, base string, header *atlasHeader, supplementaryPages []int, coverage []byte, proportional *proportionalTable, bits []byte) error {
+	rows := 0x100 + len(supplementaryPages)
+	pageSize := len(bits) / rows
+
+	var baseName string
+	var basePages [][]byte
+	if base != "" {
+		name, err := filepath.Rel(filepath.Dir(path), base)
+		if err != nil {
+			return err
+		}
+		baseName = filepath.ToSlash(name)
+
+		baseHeader, baseSupplementaryPages, pages, err := readPagedAtlas(base, pageSize)
+		if err != nil {
+			return err
+		}
+		if baseHeader.cellWidth != header.cellWidth || baseHeader.cellHeight != header.cellHeight || baseHeader.runesPerPage != header.runesPerPage {
+			return fmt.Errorf("gen: the layout of the base atlas doesn't match: %s", base)
+		}
+		if !slices.Equal(baseSupplementaryPages, supplementaryPages) {
+			return fmt.Errorf("gen: the supplementary pages of the base atlas don't match: %s", base)
+		}
+		basePages = pages
+	}
+
+	bs := appendAtlasHeader(nil, header)
+	bs = binary.BigEndian.AppendUint16(bs, uint16(len(baseName)))
+	bs = append(bs, baseName...)
+	bs = binary.BigEndian.AppendUint16(bs, uint16(len(supplementaryPages)))
+	for _, page := range supplementaryPages {
+		bs = binary.BigEndian.AppendUint16(bs, uint16(page))
+	}
+
+	c, err := compressBlock(coverage)
+	if err != nil {
+		return err
+	}
+	bs = binary.BigEndian.AppendUint32(bs, uint32(len(c)))
+	bs = append(bs, c...)
+
+	bs, err = appendProportionalTable(bs, proportional)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	for i := 0; i < rows; i++ {
+		src := bits[i*pageSize : (i+1)*pageSize]
+		empty := !slices.ContainsFunc(src, func(b byte) bool { return b != 0 })
+		if basePages != nil {
+			if (empty && basePages[i] == nil) || bytes.Equal(src, basePages[i]) {
+				bs = binary.BigEndian.AppendUint32(bs, pageSameAsBase)
+				continue
+			}
+		}
+		if empty {
+			bs = binary.BigEndian.AppendUint32(bs, 0)
+			continue
+		}
+		dst, err := compressBlock(src)
+		if err != nil {
+			return err
+		}
+		bs = binary.BigEndian.AppendUint32(bs, uint32(len(dst)))
+		body = append(body, dst...)
+	}
+
+	bs = append(bs, body...)
+	putAtlasChecksum(bs)
+	return os.WriteFile(path, bs, 0644)
+}
+
+// appendProportionalTable appends the proportional table t to bs.
+//
+// The size of the table, and the size of the compressed table as 32-bit big endian integers precede the table as an LZ4 block.
+// The table starts with the number of the glyphs as a 16-bit big endian integer.
+// Each glyph follows it as the rune as a 32-bit big endian integer, and the left position and the advance as 8-bit integers.
+// The number of the kerning pairs as a 16-bit big endian integer follows the glyphs.
+// Each pair follows it as the two runes as 32-bit big endian integers, and the kerning value as a signed 8-bit integer.
+func appendProportionalTable(bs []byte, t *proportionalTable) ([]byte, error) {
+	var table []byte
+	table = binary.BigEndian.AppendUint16(table, uint16(len(t.glyphs)))
+	for _, g := range t.glyphs {
+		table = binary.BigEndian.AppendUint32(table, uint32(g.r))
+		table = append(table, byte(g.left), byte(g.advance))
+	}
+	table = binary.BigEndian.AppendUint16(table, uint16(len(t.kerns)))
+	for _, k := range t.kerns {
+		table = binary.BigEndian.AppendUint32(table, uint32(k.r0))
+		table = binary.BigEndian.AppendUint32(table, uint32(k.r1))
+		table = append(table, byte(int8(k.kern)))
+	}
+
+	c, err := compressBlock(table)
+	if err != nil {
+		return nil, err
+	}
+	bs = binary.BigEndian.AppendUint32(bs, uint32(len(table)))
+	bs = binary.BigEndian.AppendUint32(bs, uint32(len(c)))
+	bs = append(bs, c...)
+	return bs, nil
+}
+
+// compressBlock compresses src as an LZ4 block.
+// If src cannot be compressed, compressBlock returns src as it is.
+func compressBlock(src []byte) ([]byte, error) {
+	dst := make([]byte, lz4.CompressBlockBound(len(src)))
+	n, err := lz4.CompressBlockHC(src, dst, lz4.Level9, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 || n >= len(src) {
+		return src, nil
+	}
+	return dst[:n], nil
+}
+
+// readPagedAtlas reads the atlas written by writePagedAtlas, and returns the decoded pages.
+// An empty page is nil.
+func readPagedAtlas(path string, pageSize int) (header *atlasHeader, supplementaryPages []int, pages [][]byte, err error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	header, bs, err = readAtlasHeader(bs)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s", err, path)
+	}
+
+	baseNameLen := int(binary.BigEndian.Uint16(bs))
+	baseName := string(bs[2 : 2+baseNameLen])
+	var basePages [][]byte
+	if baseName != "" {
+		_, _, pages, err := readPagedAtlas(filepath.Join(filepath.Dir(path), filepath.FromSlash(baseName)), pageSize)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		basePages = pages
+	}
+
+	h := bs[2+baseNameLen:]
+	n := int(binary.BigEndian.Uint16(h))
+	supplementaryPages = make([]int, n)
+	for i := range supplementaryPages {
+		supplementaryPages[i] = int(binary.BigEndian.Uint16(h[2+2*i:]))
+	}
+	h = h[2+2*n:]
+
+	// Skip the coverage and the proportional table.
+	coverageSize := int(binary.BigEndian.Uint32(h))
+	h = h[4+coverageSize:]
+	proportionalSize := int(binary.BigEndian.Uint32(h[4:]))
+	h = h[8+proportionalSize:]
+
+	rows := 0x100 + n
+	sizes := h
+	body := sizes[4*rows:]
+	pages = make([][]byte, rows)
+	for i := range pages {
+		size := binary.BigEndian.Uint32(sizes[4*i:])
+		switch {
+		case size == 0:
+		case size == pageSameAsBase:
+			pages[i] = basePages[i]
+		case int(size) == pageSize:
+			pages[i] = body[:size]
+		default:
+			page := make([]byte, pageSize)
+			if _, err := lz4.UncompressBlock(body[:size], page); err != nil {
+				return nil, nil, nil, err
+			}
+			pages[i] = page
+		}
+		if size != pageSameAsBase {
+			body = body[size:]
+		}
+	}
+	return header, supplementaryPages, pages, nil
+}
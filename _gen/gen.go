@@ -21,8 +21,10 @@ import (
 	"image/color"
 	"image/draw"
 	"os"
+	"path/filepath"
+	"slices"
+	stdunicode "unicode"
 
-	"github.com/pierrec/lz4/v4"
 	"golang.org/x/text/width"
 
 	"github.com/hajimehoshi/bitmapfont/v4/internal/arabic"
@@ -39,13 +41,27 @@ var (
 	flagWidths   = flag.Bool("widths", false, "output widths infomation")
 	flagOutput   = flag.String("output", "", "output file")
 	flagEastAsia = flag.Bool("eastasia", false, "prefer east Asia punctuations")
-	flagLang     = flag.String("lang", "ja", "language ('ja', 'zh-Hans', or 'zh-Hant')")
+	flagLang     = flag.String("lang", "ja", "language ('ja', 'ko', 'zh-Hans', or 'zh-Hant')")
+	flagSize     = flag.Int("size", 12, "glyph size (10 or 12)")
+	flagBase     = flag.String("base", "", "base atlas file that the output shares the same pages with")
+	flagSubset   = flag.String("subset", "", "text file or directory of text files whose runes the output has only")
+	flagPackage  = flag.String("package", "", "package name of the Go file for the subset (default: the output directory name)")
 )
 
-const (
-	glyphRegionWidth  = 12
-	glyphRegionHeight = 16
-)
+// glyphRegion returns the size of a glyph region in the output image,
+// and the Y offset in the source glyph images where the region starts.
+//
+// Source glyph images are 16px high and their baselines are at y=12.
+func glyphRegion(size int) (width, height, offsetY int) {
+	switch size {
+	case 10:
+		return 10, 13, 3
+	case 12:
+		return 12, 16, 0
+	default:
+		panic("not reached")
+	}
+}
 
 type fontType int
 
@@ -60,6 +76,30 @@ const (
 	fontTypeArk
 )
 
+// String returns the name of the source font, which is recorded in the atlas header.
+func (f fontType) String() string {
+	switch f {
+	case fontTypeNone:
+		return "none"
+	case fontTypeFixed:
+		return "fixed"
+	case fontTypeMPlus:
+		return "mplus"
+	case fontTypeBaekmuk:
+		return "baekmuk"
+	case fontTypeGalmuri:
+		return "galmuri"
+	case fontTypeArabic:
+		return "arabic"
+	case fontTypeCubic11:
+		return "cubic11"
+	case fontTypeArk:
+		return "ark"
+	default:
+		panic("not reached")
+	}
+}
+
 func getFontType(r rune) fontType {
 	// For Latin glyphs, M+ doesn't work. Use the fixed font whatever the face is.
 	if unicode.IsLatin(r) {
@@ -77,31 +117,69 @@ func getFontType(r rune) fontType {
 				// Box Drawing
 				// M+ defines a part of box drawing glyphs.
 				// For consistency, use Galmuri glyphs instead.
+				if *flagSize == 10 {
+					// Galmuri doesn't have 10px glyphs. Use Baekmuk glyphs instead.
+					return fontTypeBaekmuk
+				}
 				return fontTypeGalmuri
 			}
+			if *flagLang == "ko" {
+				// Baekmuk glyphs are fullwidth and have Korean forms.
+				if _, ok := baekmuk.Glyph(r, *flagSize); ok {
+					return fontTypeBaekmuk
+				}
+			}
 			return fontTypeMPlus
 		}
 		return fontTypeFixed
 	}
 
-	if _, ok := fixed.Glyph(r, 12); ok {
+	if _, ok := fixed.Glyph(r, *flagSize); ok {
 		return fontTypeFixed
 	}
-	if *flagLang == "ja" {
+	if *flagSize == 10 {
+		// Only M+ and Baekmuk have 10px glyphs other than the fixed font.
+		if _, ok := mplus.Glyph(r, 10); ok {
+			return fontTypeMPlus
+		}
+		if _, ok := baekmuk.Glyph(r, 10); ok {
+			return fontTypeBaekmuk
+		}
+		return fontTypeNone
+	}
+	switch *flagLang {
+	case "ja":
+		if _, ok := mplus.Glyph(r, 12); ok {
+			return fontTypeMPlus
+		}
+		if _, ok := cubic11.Glyph(r); ok {
+			return fontTypeCubic11
+		}
+		if _, ok := ark.Glyph(r, arkSimplified()); ok {
+			return fontTypeArk
+		}
+	case "ko":
+		// Prefer Korean fonts for Hangul, Hanja, and symbols.
+		if _, ok := galmuri.Glyph(r); ok {
+			return fontTypeGalmuri
+		}
+		if _, ok := baekmuk.Glyph(r, 12); ok {
+			return fontTypeBaekmuk
+		}
 		if _, ok := mplus.Glyph(r, 12); ok {
 			return fontTypeMPlus
 		}
 		if _, ok := cubic11.Glyph(r); ok {
 			return fontTypeCubic11
 		}
-		if _, ok := ark.Glyph(r, true); ok {
+		if _, ok := ark.Glyph(r, arkSimplified()); ok {
 			return fontTypeArk
 		}
-	} else {
+	default:
 		if _, ok := cubic11.Glyph(r); ok {
 			return fontTypeCubic11
 		}
-		if _, ok := ark.Glyph(r, *flagLang != "zh-Hant"); ok {
+		if _, ok := ark.Glyph(r, arkSimplified()); ok {
 			return fontTypeArk
 		}
 		if _, ok := mplus.Glyph(r, 12); ok {
@@ -121,20 +199,39 @@ func getFontType(r rune) fontType {
 	return fontTypeNone
 }
 
+// arkSimplified reports whether simplified Chinese glyphs of Ark Pixel Font are preferred.
+// Korean Hanja have traditional forms.
+func arkSimplified() bool {
+	return *flagLang != "zh-Hant" && *flagLang != "ko"
+}
+
+// subsetRunes is the runes that the output has. If subsetRunes is nil, the output has all the runes.
+var subsetRunes map[rune]struct{}
+
 func getGlyph(r rune) (image.Image, bool) {
+	if subsetRunes != nil {
+		if _, ok := subsetRunes[r]; !ok {
+			return nil, false
+		}
+	}
+	return sourceGlyph(r)
+}
+
+// sourceGlyph returns the glyph for r from the source fonts regardless of the subset.
+func sourceGlyph(r rune) (image.Image, bool) {
 	switch getFontType(r) {
 	case fontTypeNone:
 		return nil, false
 	case fontTypeFixed:
-		if g, ok := fixed.Glyph(r, 12); ok {
+		if g, ok := fixed.Glyph(r, *flagSize); ok {
 			return g, true
 		}
 	case fontTypeMPlus:
-		if g, ok := mplus.Glyph(r, 12); ok {
+		if g, ok := mplus.Glyph(r, *flagSize); ok {
 			return g, true
 		}
 	case fontTypeBaekmuk:
-		if g, ok := baekmuk.Glyph(r, 12); ok {
+		if g, ok := baekmuk.Glyph(r, *flagSize); ok {
 			return g, true
 		}
 	case fontTypeGalmuri:
@@ -150,7 +247,7 @@ func getGlyph(r rune) (image.Image, bool) {
 			return g, true
 		}
 	case fontTypeArk:
-		if g, ok := ark.Glyph(r, *flagLang != "zh-Hant"); ok {
+		if g, ok := ark.Glyph(r, arkSimplified()); ok {
 			return g, true
 		}
 	default:
@@ -159,21 +256,57 @@ func getGlyph(r rune) (image.Image, bool) {
 	return nil, false
 }
 
-func addGlyphs(img draw.Image) {
-	for j := 0; j < 0x100; j++ {
+// supplementaryPages returns the pages that have any glyphs in the supplementary planes.
+// A page is a range of 256 runes, and the page number is r / 256.
+func supplementaryPages() []int {
+	var pages []int
+	for page := 0x100; page < 0x1100; page++ {
+		for i := 0; i < 0x100; i++ {
+			if _, ok := getGlyph(rune(page*0x100 + i)); ok {
+				pages = append(pages, page)
+				break
+			}
+		}
+	}
+	return pages
+}
+
+// addGlyphs adds glyphs to img, and sets the bits of the runes that have glyphs in coverage.
+// img has 256 rows for the BMP and one row for each supplementary page.
+// coverage has 256 bits for each row in the same order.
+//
+// addGlyphs returns the source fonts of the glyphs.
+func addGlyphs(img draw.Image, coverage []byte, supplementaryPages []int) []fontType {
+	glyphRegionWidth, glyphRegionHeight, offsetY := glyphRegion(*flagSize)
+	pages := make([]int, 0, 0x100+len(supplementaryPages))
+	for page := 0; page < 0x100; page++ {
+		pages = append(pages, page)
+	}
+	pages = append(pages, supplementaryPages...)
+
+	var sources []fontType
+	for j, page := range pages {
 		for i := 0; i < 0x100; i++ {
-			r := rune(i + j*0x100)
+			r := rune(i + page*0x100)
 			g, ok := getGlyph(r)
 			if !ok {
 				continue
 			}
+			if t := getFontType(r); !slices.Contains(sources, t) {
+				sources = append(sources, t)
+			}
+
+			idx := j*0x100 + i
+			coverage[idx/8] |= 1 << uint(7-idx%8)
 
 			dstX := i * glyphRegionWidth
 			dstY := j * glyphRegionHeight
 			dstR := image.Rect(dstX, dstY, dstX+glyphRegionWidth, dstY+glyphRegionHeight)
-			draw.Draw(img, dstR, g, image.Point{}, draw.Over)
+			draw.Draw(img, dstR, g, image.Pt(0, offsetY), draw.Over)
 		}
 	}
+	slices.Sort(sources)
+	return sources
 }
 
 func run() error {
@@ -181,8 +314,39 @@ func run() error {
 		return outputWidths()
 	}
 
-	img := image.NewAlpha(image.Rect(0, 0, glyphRegionWidth*256, glyphRegionHeight*256))
-	addGlyphs(img)
+	if *flagSize != 10 && *flagSize != 12 {
+		return fmt.Errorf("gen: unsupported size: %d", *flagSize)
+	}
+	switch *flagLang {
+	case "ja", "ko", "zh-Hans", "zh-Hant":
+	default:
+		return fmt.Errorf("gen: unsupported language: %q", *flagLang)
+	}
+	if *flagSize == 10 && *flagLang != "ja" {
+		// Cubic 11, Ark Pixel Font, and Galmuri don't have 10px glyphs.
+		return fmt.Errorf("gen: language %q is not supported for size 10", *flagLang)
+	}
+	if filepath.Ext(*flagOutput) != ".bin" {
+		return fmt.Errorf("gen: the output file name must end with .bin: %s", *flagOutput)
+	}
+
+	if *flagSubset != "" {
+		if *flagBase != "" {
+			return fmt.Errorf("gen: -base cannot be used with -subset")
+		}
+		rs, err := readSubsetRunes(*flagSubset)
+		if err != nil {
+			return err
+		}
+		subsetRunes = rs
+	}
+
+	pages := supplementaryPages()
+
+	glyphRegionWidth, glyphRegionHeight, offsetY := glyphRegion(*flagSize)
+	img := image.NewAlpha(image.Rect(0, 0, glyphRegionWidth*256, glyphRegionHeight*(256+len(pages))))
+	coverage := make([]byte, (256+len(pages))*256/8)
+	sources := addGlyphs(img, coverage, pages)
 
 	b := img.Bounds()
 	w, h := b.Dx(), b.Dy()
@@ -197,33 +361,87 @@ func run() error {
 		}
 	}
 
-	fout, err := os.Create(*flagOutput)
-	if err != nil {
+	header := &atlasHeader{
+		size:       *flagSize,
+		cellWidth:  glyphRegionWidth,
+		cellHeight: glyphRegionHeight,
+		// The baseline is at y=12 in the source glyph images.
+		dotY:          12 - offsetY,
+		runesPerPage:  0x100,
+		eastAsianWide: *flagEastAsia,
+		language:      *flagLang,
+	}
+	for _, s := range sources {
+		header.sources = append(header.sources, s.String())
+	}
+	if err := measureMetrics(header); err != nil {
 		return err
 	}
-	defer fout.Close()
-
-	cw := lz4.NewWriter(fout)
-	defer cw.Close()
-
-	cw.Apply(lz4.CompressionLevelOption(lz4.Level9))
-
-	if _, err := cw.Write(as); err != nil {
+	if err := writePagedAtlas(*flagOutput, *flagBase, header, pages, coverage, measureProportionalTable(), as); err != nil {
 		return err
 	}
+
+	if subsetRunes != nil {
+		if err := outputSubsetGoFile(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// runeClass returns the width class of r for the BMP.
+// The result must be consistent with the glyphs in the atlas.
+func runeClass(r rune, wideArabicRunes map[rune]struct{}) byte {
+	var class byte
+	switch {
+	case unicode.IsLatin(r):
+		// For Latin glyphs, M+ doesn't work. The fixed font is used whatever the face is.
+		class = runeClassHalfwidth
+	default:
+		if _, ok := wideArabicRunes[r]; ok {
+			class = runeClassFullwidth
+			break
+		}
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianAmbiguous:
+			class = runeClassAmbiguous
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			class = runeClassFullwidth
+		default:
+			class = runeClassHalfwidth
+		}
+	}
+	if stdunicode.Is(stdunicode.Mn, r) {
+		class |= runeClassNonspacing
+	}
+	return class
+}
+
+// The rune classes must be the same as the ones in internal/bitmap/runeclass.go.
+const (
+	runeClassHalfwidth = 0
+	runeClassFullwidth = 1
+	runeClassAmbiguous = 2
+
+	runeClassWidthMask  = 0x3
+	runeClassNonspacing = 0x4
+)
+
 func outputWidths() error {
-	var wideRunes []rune
-	for r := rune(0); r <= 0xffff; r++ {
+	glyphRegionWidth, _, _ := glyphRegion(*flagSize)
+	wideArabicRunes := map[rune]struct{}{}
+	for r := rune(0); r <= 0x10ffff; r++ {
 		img, ok := arabic.Glyph(r)
 		if !ok {
 			continue
 		}
-		if img.Bounds().Dx() == glyphRegionWidth {
-			wideRunes = append(wideRunes, r)
+		if img.Bounds().Dx() != glyphRegionWidth {
+			continue
 		}
+		if r > 0xffff {
+			return fmt.Errorf("gen: wide Arabic glyphs must be in the BMP: %U", r)
+		}
+		wideArabicRunes[r] = struct{}{}
 	}
 
 	f, err := os.Create(*flagOutput)
@@ -236,9 +454,17 @@ func outputWidths() error {
 	fmt.Fprintln(f, "")
 	fmt.Fprintln(f, "package bitmap")
 	fmt.Fprintln(f, "")
-	fmt.Fprintln(f, "var wideRunes = map[rune]struct{}{")
-	for _, r := range wideRunes {
-		fmt.Fprintf(f, "\t0x%04x: {},\n", r)
+	fmt.Fprintln(f, "// bmpRuneClasses is the classes of the BMP runes, packed into 4 bits per rune.")
+	fmt.Fprintln(f, "// The lower 4 bits of a byte are for an even rune, and the upper 4 bits are for the next odd rune.")
+	fmt.Fprintln(f, "var bmpRuneClasses = [0x10000 / 2]byte{")
+	const runesPerLine = 32
+	for r := rune(0); r <= 0xffff; r += runesPerLine {
+		fmt.Fprintf(f, "\t/* U+%04X */", r)
+		for i := rune(0); i < runesPerLine; i += 2 {
+			c := runeClass(r+i, wideArabicRunes) | runeClass(r+i+1, wideArabicRunes)<<4
+			fmt.Fprintf(f, " 0x%02x,", c)
+		}
+		fmt.Fprintln(f)
 	}
 	fmt.Fprintln(f, "}")
 
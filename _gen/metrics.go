@@ -0,0 +1,85 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+// inkHeight returns the height of the ink of r above the baseline.
+func inkHeight(r rune) (int, error) {
+	g, ok := sourceGlyph(r)
+	if !ok {
+		return 0, fmt.Errorf("gen: glyph not found: %q", r)
+	}
+	p := measureInk(g)
+	_, _, offsetY := glyphRegion(*flagSize)
+	for j := range p.left {
+		if p.left[j] != -1 {
+			// The baseline is at y=12 in the source glyph images.
+			return 12 - offsetY - j, nil
+		}
+	}
+	return 0, fmt.Errorf("gen: glyph has no ink: %q", r)
+}
+
+// caretSlope returns the slope of the caret as the horizontal and vertical distances between the ends of the vertical line glyph.
+func caretSlope() (run, rise int, err error) {
+	g, ok := sourceGlyph('|')
+	if !ok {
+		return 0, 0, fmt.Errorf("gen: glyph not found: %q", '|')
+	}
+	p := measureInk(g)
+	top, bottom := -1, -1
+	for j := range p.left {
+		if p.left[j] == -1 {
+			continue
+		}
+		if top == -1 {
+			top = j
+		}
+		bottom = j
+	}
+	if top == -1 {
+		return 0, 0, fmt.Errorf("gen: glyph has no ink: %q", '|')
+	}
+	run = p.left[top] - p.left[bottom]
+	if run == 0 {
+		return 0, 1, nil
+	}
+	return run, bottom - top, nil
+}
+
+// measureMetrics sets the metrics measured from the glyphs to h.
+// The metrics are measured regardless of the subset.
+func measureMetrics(h *atlasHeader) error {
+	capHeight, err := inkHeight('H')
+	if err != nil {
+		return err
+	}
+	xHeight, err := inkHeight('x')
+	if err != nil {
+		return err
+	}
+	run, rise, err := caretSlope()
+	if err != nil {
+		return err
+	}
+	h.capHeight = capHeight
+	h.xHeight = xHeight
+	h.caretSlopeRun = run
+	h.caretSlopeRise = rise
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	stdunicode "unicode"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/unicode"
+)
+
+// kernRunes are the runes whose pairs are examined for kerning.
+const kernRunes = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz.,"
+
+// inkProfile represents the ink of a glyph in a halfwidth glyph region.
+type inkProfile struct {
+	// left and right are the leftmost and the rightmost ink columns of each row, or -1 if the row has no ink.
+	left  []int
+	right []int
+
+	// minX and maxX are the leftmost and the rightmost ink columns of the glyph, or -1 if the glyph has no ink.
+	minX int
+	maxX int
+}
+
+// measureInk measures the ink of the glyph image g in its halfwidth glyph region.
+func measureInk(g image.Image) inkProfile {
+	glyphRegionWidth, glyphRegionHeight, offsetY := glyphRegion(*flagSize)
+	p := inkProfile{
+		left:  make([]int, glyphRegionHeight),
+		right: make([]int, glyphRegionHeight),
+		minX:  -1,
+		maxX:  -1,
+	}
+	for j := 0; j < glyphRegionHeight; j++ {
+		p.left[j] = -1
+		p.right[j] = -1
+		for i := 0; i < glyphRegionWidth/2; i++ {
+			if g.At(i, j+offsetY).(color.Alpha).A == 0 {
+				continue
+			}
+			if p.left[j] == -1 {
+				p.left[j] = i
+			}
+			p.right[j] = i
+			if p.minX == -1 || i < p.minX {
+				p.minX = i
+			}
+			if p.maxX < i {
+				p.maxX = i
+			}
+		}
+	}
+	return p
+}
+
+// proportionalRune reports whether r has a proportional advance.
+func proportionalRune(r rune) bool {
+	if stdunicode.Is(stdunicode.Mn, r) || stdunicode.Is(stdunicode.Me, r) {
+		return false
+	}
+	if !unicode.IsLatin(r) && !unicode.IsGreek(r) && !unicode.IsCyrillic(r) {
+		return false
+	}
+	// Only halfwidth glyphs have proportional advances.
+	class := runeClass(r, nil) & runeClassWidthMask
+	if class == runeClassFullwidth {
+		return false
+	}
+	if *flagEastAsia && class == runeClassAmbiguous {
+		return false
+	}
+	return true
+}
+
+// advance returns the proportional advance of the glyph.
+// A glyph has one empty column on its right side.
+func (p *inkProfile) advance() int {
+	if p.minX == -1 {
+		// Use a narrower space than the halfwidth space.
+		glyphRegionWidth, _, _ := glyphRegion(*flagSize)
+		return glyphRegionWidth / 2 * 2 / 3
+	}
+	return p.maxX - p.minX + 2
+}
+
+// kern returns the kerning value for the pair of p0 and p1.
+//
+// A pair is kerned by one pixel when at least three empty columns are left between the glyphs at every row,
+// including the diagonally adjacent rows.
+func kern(p0, p1 *inkProfile) int {
+	if p0.minX == -1 || p1.minX == -1 {
+		return 0
+	}
+	minGap := -1
+	for j1 := range p1.left {
+		if p1.left[j1] == -1 {
+			continue
+		}
+		x1 := p0.advance() + p1.left[j1] - p1.minX
+		for j0 := max(j1-1, 0); j0 <= min(j1+1, len(p0.right)-1); j0++ {
+			if p0.right[j0] == -1 {
+				continue
+			}
+			x0 := p0.right[j0] - p0.minX
+			if gap := x1 - x0 - 1; minGap == -1 || gap < minGap {
+				minGap = gap
+			}
+		}
+	}
+	if minGap < 3 {
+		return 0
+	}
+	return -1
+}
+
+// proportionalGlyph is the horizontal ink position and the advance of a glyph in its halfwidth glyph region.
+type proportionalGlyph struct {
+	r       rune
+	left    int
+	advance int
+}
+
+// proportionalKern is the kerning value for a pair of proportional glyphs.
+type proportionalKern struct {
+	r0   rune
+	r1   rune
+	kern int
+}
+
+// proportionalTable is the proportional glyphs of an atlas and their kerning values.
+type proportionalTable struct {
+	// glyphs is sorted by the runes.
+	glyphs []proportionalGlyph
+
+	// kerns is sorted by the pairs of the runes.
+	kerns []proportionalKern
+}
+
+// measureProportionalTable measures the proportional glyphs in the output atlas.
+func measureProportionalTable() *proportionalTable {
+	profiles := map[rune]*inkProfile{}
+	t := &proportionalTable{}
+	for r := rune(0); r <= 0xffff; r++ {
+		if !proportionalRune(r) {
+			continue
+		}
+		g, ok := getGlyph(r)
+		if !ok {
+			continue
+		}
+		p := measureInk(g)
+		profiles[r] = &p
+		t.glyphs = append(t.glyphs, proportionalGlyph{
+			r:       r,
+			left:    max(p.minX, 0),
+			advance: p.advance(),
+		})
+	}
+
+	rs := []rune(kernRunes)
+	slices.Sort(rs)
+	for _, r0 := range rs {
+		p0, ok := profiles[r0]
+		if !ok {
+			continue
+		}
+		for _, r1 := range rs {
+			p1, ok := profiles[r1]
+			if !ok {
+				continue
+			}
+			if k := kern(p0, p1); k != 0 {
+				t.kerns = append(t.kerns, proportionalKern{r0: r0, r1: r1, kern: k})
+			}
+		}
+	}
+	return t
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// readSubsetRunes returns the runes in the file at path.
+// If path is a directory, readSubsetRunes returns the runes in all the files in the directory recursively.
+func readSubsetRunes(path string) (map[rune]struct{}, error) {
+	runes := map[rune]struct{}{}
+	if err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(bs) {
+			return fmt.Errorf("gen: the file is not valid UTF-8: %s", path)
+		}
+		for _, r := range string(bs) {
+			runes[r] = struct{}{}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return runes, nil
+}
+
+// outputSubsetGoFile writes a Go file exposing the subset face next to the output file, e.g., face.go for face.bin.
+func outputSubsetGoFile() error {
+	dir, err := filepath.Abs(filepath.Dir(*flagOutput))
+	if err != nil {
+		return err
+	}
+	pkg := *flagPackage
+	if pkg == "" {
+		pkg = filepath.Base(dir)
+	}
+
+	binFile := filepath.Base(*flagOutput)
+
+	var ea string
+	if *flagEastAsia {
+		ea = " and East Asia wide characters"
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, "// Code generated by github.com/hajimehoshi/bitmapfont/internal/_gen. DO NOT EDIT.")
+	fmt.Fprintln(&b, "")
+	fmt.Fprintf(&b, "package %s\n", pkg)
+	fmt.Fprintln(&b, "")
+	fmt.Fprintln(&b, "import (")
+	fmt.Fprintln(&b, "\t\"embed\"")
+	fmt.Fprintln(&b, "")
+	fmt.Fprintln(&b, "\t\"golang.org/x/image/font\"")
+	fmt.Fprintln(&b, "")
+	fmt.Fprintln(&b, "\t\"github.com/hajimehoshi/bitmapfont/v4\"")
+	fmt.Fprintln(&b, ")")
+	fmt.Fprintln(&b, "")
+	fmt.Fprintf(&b, "//go:embed %s\n", binFile)
+	fmt.Fprintln(&b, "var faceData embed.FS")
+	fmt.Fprintln(&b, "")
+	fmt.Fprintf(&b, "// Face is a font.Face of the subset of the bitmap font (%dpx regular, language %s%s).\n", *flagSize, *flagLang, ea)
+	fmt.Fprintf(&b, "var Face font.Face = bitmapfont.NewSubsetFace(faceData, %q)\n", binFile)
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(strings.TrimSuffix(*flagOutput, ".bin")+".go", src, 0644)
+}
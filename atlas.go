@@ -0,0 +1,545 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"sync"
+	"unicode"
+	"weak"
+
+	"github.com/pierrec/lz4/v4"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+// pageSameAsBase is the size of a page that is the same as the page of the base atlas.
+const pageSameAsBase = 0xffffffff
+
+const (
+	atlasMagic   = "BMFA"
+	atlasVersion = 3
+)
+
+const (
+	// maxAtlasFileSize is the maximum size of an atlas file in bytes.
+	// This is much larger than the atlases that the generator outputs.
+	maxAtlasFileSize = 64 << 20
+
+	// maxAtlasBitsSize is the maximum size of the decoded bits of an atlas in bytes.
+	maxAtlasBitsSize = 256 << 20
+)
+
+// atlasHeader describes an atlas.
+type atlasHeader struct {
+	// size is the glyph size in pixels like 12.
+	size int
+
+	// cellWidth and cellHeight are the size of a fullwidth glyph region.
+	cellWidth  int
+	cellHeight int
+
+	// dotY is the baseline position in a glyph region.
+	dotY int
+
+	// runesPerPage is the number of runes in a page. A page is a row of the atlas.
+	runesPerPage int
+
+	eastAsianWide bool
+
+	// capHeight and xHeight are the heights of the ink of 'H' and 'x' above the baseline.
+	capHeight int
+	xHeight   int
+
+	// caretSlopeRun and caretSlopeRise are the slope of the caret.
+	caretSlopeRun  int
+	caretSlopeRise int
+
+	language string
+
+	// sources is the names of the fonts that the glyphs come from.
+	sources []string
+}
+
+// pageSize returns the size of the bits of a page in bytes.
+func (h *atlasHeader) pageSize() int {
+	return h.cellWidth * h.runesPerPage * h.cellHeight / 8
+}
+
+// atlasReader reads the big endian integers and the strings of an atlas.
+// atlasReader returns zero values after the data is exhausted, and reports it by truncated.
+type atlasReader struct {
+	bs        []byte
+	truncated bool
+}
+
+func (r *atlasReader) next(n int) []byte {
+	if r.truncated || len(r.bs) < n {
+		r.truncated = true
+		return make([]byte, n)
+	}
+	bs := r.bs[:n]
+	r.bs = r.bs[n:]
+	return bs
+}
+
+func (r *atlasReader) uint8() int {
+	return int(r.next(1)[0])
+}
+
+func (r *atlasReader) int8() int {
+	return int(int8(r.next(1)[0]))
+}
+
+func (r *atlasReader) uint16() int {
+	return int(binary.BigEndian.Uint16(r.next(2)))
+}
+
+func (r *atlasReader) uint32() uint32 {
+	return binary.BigEndian.Uint32(r.next(4))
+}
+
+// string8 reads a string that starts with its length as an 8-bit integer.
+func (r *atlasReader) string8() string {
+	return string(r.next(r.uint8()))
+}
+
+// string16 reads a string that starts with its length as a 16-bit integer.
+func (r *atlasReader) string16() string {
+	return string(r.next(r.uint16()))
+}
+
+// readAtlasHeader reads the header of an atlas generated by _gen.
+//
+// The header starts with the magic "BMFA", the version as a 16-bit big endian integer,
+// and the CRC-32 (IEEE) checksum of all the following bytes of the file as a 32-bit big endian integer.
+// The size, the cell width, the cell height, and the baseline position follow them as 8-bit integers.
+// The number of the runes in a page as a 16-bit big endian integer and the flags as an 8-bit integer follow them.
+// The only flag is 1 for East Asian wide.
+// The cap height and the x-height as 8-bit integers follow them.
+// The run and the rise of the caret slope as signed 8-bit integers follow them.
+// The language and the sources follow them as strings that start with their lengths as 8-bit integers.
+// The number of the sources as an 8-bit integer precedes the sources.
+func readAtlasHeader(r *atlasReader) (*atlasHeader, error) {
+	if string(r.next(len(atlasMagic))) != atlasMagic {
+		return nil, fmt.Errorf("not an atlas")
+	}
+	if v := r.uint16(); v != atlasVersion {
+		return nil, fmt.Errorf("unsupported version: %d", v)
+	}
+	if checksum := r.uint32(); r.truncated || checksum != crc32.ChecksumIEEE(r.bs) {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+
+	h := &atlasHeader{
+		size:       r.uint8(),
+		cellWidth:  r.uint8(),
+		cellHeight: r.uint8(),
+		dotY:       r.uint8(),
+	}
+	h.runesPerPage = r.uint16()
+	h.eastAsianWide = r.uint8()&1 != 0
+	h.capHeight = r.uint8()
+	h.xHeight = r.uint8()
+	h.caretSlopeRun = r.int8()
+	h.caretSlopeRise = r.int8()
+	h.language = r.string8()
+	for range r.uint8() {
+		h.sources = append(h.sources, r.string8())
+	}
+	if r.truncated {
+		return nil, fmt.Errorf("truncated header")
+	}
+
+	if h.cellWidth == 0 || h.cellHeight == 0 || h.dotY > h.cellHeight {
+		return nil, fmt.Errorf("invalid cell: %dx%d, baseline %d", h.cellWidth, h.cellHeight, h.dotY)
+	}
+	// A halfwidth glyph region is the half of a cell.
+	if h.cellWidth%2 != 0 {
+		return nil, fmt.Errorf("odd cell width: %d", h.cellWidth)
+	}
+	// The layout of the rows depends on the fixed number of runes in a page. See bitmap.Coverage.
+	if h.runesPerPage != 256 {
+		return nil, fmt.Errorf("unsupported page layout: %d runes per page", h.runesPerPage)
+	}
+	return h, nil
+}
+
+// readAtlasData reads an atlas from r.
+// readAtlasData returns an error if the data is too large.
+func readAtlasData(r io.Reader, name string) ([]byte, error) {
+	bs, err := io.ReadAll(io.LimitReader(r, maxAtlasFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(bs) > maxAtlasFileSize {
+		return nil, fmt.Errorf("bitmapfont: atlas %s is too large", name)
+	}
+	return bs, nil
+}
+
+// readAtlasFile reads an atlas file in fsys.
+// readAtlasFile returns an error if the file is too large.
+func readAtlasFile(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if st, err := f.Stat(); err == nil && st.Size() > maxAtlasFileSize {
+		return nil, fmt.Errorf("bitmapfont: atlas %s is too large", name)
+	}
+	return readAtlasData(f, name)
+}
+
+// pagedAtlas is an atlas whose pages are compressed independently.
+// A page is a row of the atlas, which has glyphs for 256 runes.
+type pagedAtlas struct {
+	// name is the name of the atlas file.
+	name string
+
+	header             *atlasHeader
+	supplementaryPages []int
+	coverage           *bitmap.Coverage
+	pageSize           int
+
+	proportionalGlyphs map[rune]bitmap.ProportionalGlyph
+	proportionalKerns  map[[2]rune]int
+
+	// pages is the compressed pages. An empty page is nil.
+	pages [][]byte
+
+	// sameAsBase reports whether the page is the same as the base's.
+	sameAsBase []bool
+
+	// base is the atlas that the pages not in this atlas come from. base can be nil.
+	base *pagedAtlas
+
+	// decoded is the decoded pages, which are shared by the atlases that have this atlas as their base.
+	decoded []decodedPage
+}
+
+// decodedPage is a page decoded when it is used for the first time.
+type decodedPage struct {
+	once sync.Once
+	bits []byte
+	err  error
+}
+
+// atlasCache is a cache of the paged atlases by their names.
+//
+// An atlas is shared by the faces using it directly or as a base while any of them is alive,
+// so that the atlas is read and its pages are decoded only once.
+type atlasCache struct {
+	atlases map[string]weak.Pointer[pagedAtlas]
+	m       sync.Mutex
+}
+
+// embeddedAtlases is the cache of the embedded atlases.
+var embeddedAtlases = &atlasCache{}
+
+// get returns the cached atlas name, or nil if there is no cached atlas.
+// get returns nil if c is nil.
+func (c *atlasCache) get(name string) *pagedAtlas {
+	if c == nil {
+		return nil
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.atlases[name].Value()
+}
+
+// add adds the atlas a for name to the cache, and returns the atlas to use.
+// If an atlas for name is already cached, add returns the cached one instead of a.
+func (c *atlasCache) add(name string, a *pagedAtlas) *pagedAtlas {
+	if c == nil {
+		return a
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if cached := c.atlases[name].Value(); cached != nil {
+		return cached
+	}
+	if c.atlases == nil {
+		c.atlases = map[string]weak.Pointer[pagedAtlas]{}
+	}
+	c.atlases[name] = weak.Make(a)
+	return a
+}
+
+// maxAtlasBaseDepth is the maximum number of the base atlases in a chain.
+// The generator outputs at most two levels.
+const maxAtlasBaseDepth = 4
+
+// readPagedAtlas reads the atlas name and its base atlases with readFile.
+// The atlases are shared with cache. cache can be nil.
+func readPagedAtlas(readFile func(name string) ([]byte, error), cache *atlasCache, name string) (*pagedAtlas, error) {
+	return readPagedAtlasChain(readFile, cache, name, nil)
+}
+
+// readPagedAtlasChain reads the atlas name and its base atlases with readFile.
+// dependents is the names of the atlases that have name as their base directly or indirectly.
+func readPagedAtlasChain(readFile func(name string) ([]byte, error), cache *atlasCache, name string, dependents []string) (*pagedAtlas, error) {
+	if slices.Contains(dependents, name) {
+		return nil, fmt.Errorf("bitmapfont: invalid atlas %s: circular bases", dependents[0])
+	}
+	if len(dependents) > maxAtlasBaseDepth {
+		return nil, fmt.Errorf("bitmapfont: invalid atlas %s: too many levels of bases", dependents[0])
+	}
+	if a := cache.get(name); a != nil {
+		return a, nil
+	}
+
+	a, baseName, err := readAtlas(readFile, name)
+	if err != nil {
+		return nil, err
+	}
+	if baseName == "" {
+		if slices.Contains(a.sameAsBase, true) {
+			return nil, fmt.Errorf("bitmapfont: invalid atlas %s: a page refers to no base", name)
+		}
+		return cache.add(name, a), nil
+	}
+
+	base, err := readPagedAtlasChain(readFile, cache, path.Join(path.Dir(name), baseName), append(dependents, name))
+	if err != nil {
+		return nil, err
+	}
+	if base.header.cellWidth != a.header.cellWidth || base.header.cellHeight != a.header.cellHeight || base.header.runesPerPage != a.header.runesPerPage {
+		return nil, fmt.Errorf("bitmapfont: invalid atlas %s: the layout doesn't match with the base %s", name, baseName)
+	}
+	if !slices.Equal(a.supplementaryPages, base.supplementaryPages) {
+		return nil, fmt.Errorf("bitmapfont: invalid atlas %s: the supplementary pages don't match with the base %s", name, baseName)
+	}
+	a.base = base
+	return cache.add(name, a), nil
+}
+
+// readAtlas reads the atlas generated by _gen without its base.
+// readAtlas returns the name of the base atlas, which is empty if there is no base.
+//
+// The data starts with the header. See readAtlasHeader.
+// The length of the base file name as a 16-bit big endian integer and the name follow it.
+// The name is relative to the directory of the atlas, and is empty if there is no base.
+// The number of the supplementary pages and the page numbers as 16-bit big endian integers follow it.
+// The size of the compressed coverage as a 32-bit big endian integer and the coverage as an LZ4 block follow them.
+// The coverage has 256 bits for each row, and a bit is set when the rune has a glyph.
+// The proportional table follows it. See readProportionalTable.
+// The sizes of the compressed pages follow it as 32-bit big endian integers.
+// The compressed pages as LZ4 blocks follow the sizes.
+//
+// A block that cannot be compressed is stored as it is, and its size is the same as the uncompressed size.
+func readAtlas(readFile func(name string) ([]byte, error), name string) (atlas *pagedAtlas, baseName string, err error) {
+	bs, err := readFile(name)
+	if err != nil {
+		return nil, "", err
+	}
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("bitmapfont: invalid atlas %s: %s", name, fmt.Sprintf(format, args...))
+	}
+
+	r := &atlasReader{bs: bs}
+	header, err := readAtlasHeader(r)
+	if err != nil {
+		return nil, "", invalid("%v", err)
+	}
+	baseName = r.string16()
+
+	n := r.uint16()
+	supplementaryPages := make([]int, n)
+	for i := range supplementaryPages {
+		supplementaryPages[i] = r.uint16()
+	}
+	if r.truncated {
+		return nil, "", invalid("truncated index")
+	}
+	for i, page := range supplementaryPages {
+		if page < 0x100 || page > unicode.MaxRune/0x100 || (i > 0 && page <= supplementaryPages[i-1]) {
+			return nil, "", invalid("invalid supplementary page: %d", page)
+		}
+	}
+	rows := 256 + n
+	if rows*header.pageSize() > maxAtlasBitsSize {
+		return nil, "", invalid("too large image: %dx%d", header.cellWidth*header.runesPerPage, header.cellHeight*rows)
+	}
+
+	coverageSize := int(r.uint32())
+	if coverageSize > rows*256/8 {
+		return nil, "", invalid("too large coverage: %d", coverageSize)
+	}
+	coverageBits, err := decodeBlock(r.next(coverageSize), rows*256/8)
+	if err != nil {
+		return nil, "", invalid("broken coverage: %v", err)
+	}
+
+	glyphs, kerns, err := readProportionalTable(r, header)
+	if err != nil {
+		return nil, "", invalid("%v", err)
+	}
+
+	a := &pagedAtlas{
+		name:               name,
+		header:             header,
+		supplementaryPages: supplementaryPages,
+		coverage:           bitmap.NewCoverage(supplementaryPages, coverageBits),
+		pageSize:           header.pageSize(),
+		pages:              make([][]byte, rows),
+		sameAsBase:         make([]bool, rows),
+		decoded:            make([]decodedPage, rows),
+		proportionalGlyphs: glyphs,
+		proportionalKerns:  kerns,
+	}
+
+	sizes := make([]uint32, rows)
+	for i := range sizes {
+		sizes[i] = r.uint32()
+	}
+	if r.truncated {
+		return nil, "", invalid("truncated index")
+	}
+
+	for i, size := range sizes {
+		if size == 0 {
+			continue
+		}
+		if size == pageSameAsBase {
+			a.sameAsBase[i] = true
+			continue
+		}
+		if int(size) > a.pageSize {
+			return nil, "", invalid("too large page %d: %d", i, size)
+		}
+		a.pages[i] = r.next(int(size))
+	}
+	if r.truncated {
+		return nil, "", invalid("truncated pages")
+	}
+	if len(r.bs) > 0 {
+		return nil, "", invalid("trailing data: %d bytes", len(r.bs))
+	}
+	return a, baseName, nil
+}
+
+// maxProportionalTableSize is the maximum size of a decoded proportional table in bytes.
+const maxProportionalTableSize = 2 + 0xffff*6 + 2 + 0xffff*9
+
+// readProportionalTable reads the proportional glyphs and their kerning values.
+//
+// The size of the table, and the size of the compressed table as 32-bit big endian integers precede the table as an LZ4 block.
+// The table starts with the number of the glyphs as a 16-bit big endian integer.
+// Each glyph follows it as the rune as a 32-bit big endian integer, and the left position and the advance as 8-bit integers.
+// The number of the kerning pairs as a 16-bit big endian integer follows the glyphs.
+// Each pair follows it as the two runes as 32-bit big endian integers, and the kerning value as a signed 8-bit integer.
+func readProportionalTable(r *atlasReader, header *atlasHeader) (map[rune]bitmap.ProportionalGlyph, map[[2]rune]int, error) {
+	size := int(r.uint32())
+	compressedSize := int(r.uint32())
+	if size > maxProportionalTableSize || compressedSize > size {
+		return nil, nil, fmt.Errorf("invalid proportional table size: %d", size)
+	}
+	bs, err := decodeBlock(r.next(compressedSize), size)
+	if r.truncated {
+		return nil, nil, fmt.Errorf("truncated proportional table")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("broken proportional table: %v", err)
+	}
+
+	t := &atlasReader{bs: bs}
+	glyphs := map[rune]bitmap.ProportionalGlyph{}
+	for range t.uint16() {
+		c := rune(t.uint32())
+		g := bitmap.ProportionalGlyph{
+			Left:    t.uint8(),
+			Advance: t.uint8(),
+		}
+		if c > unicode.MaxRune || g.Left >= header.cellWidth/2 || g.Advance < 1 || g.Advance > header.cellWidth {
+			return nil, nil, fmt.Errorf("invalid proportional glyph: %U", c)
+		}
+		glyphs[c] = g
+	}
+	kerns := map[[2]rune]int{}
+	for range t.uint16() {
+		r0, r1 := rune(t.uint32()), rune(t.uint32())
+		if r0 > unicode.MaxRune || r1 > unicode.MaxRune {
+			return nil, nil, fmt.Errorf("invalid kerning pair: %U, %U", r0, r1)
+		}
+		kerns[[2]rune{r0, r1}] = t.int8()
+	}
+	if t.truncated || len(t.bs) > 0 {
+		return nil, nil, fmt.Errorf("broken proportional table")
+	}
+	return glyphs, kerns, nil
+}
+
+// decodeBlock decodes an LZ4 block whose decoded size is size.
+// A block whose size is the same as size is not compressed.
+func decodeBlock(src []byte, size int) ([]byte, error) {
+	if len(src) == size {
+		return src, nil
+	}
+	dst := make([]byte, size)
+	n, err := lz4.UncompressBlock(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if n != size {
+		return nil, fmt.Errorf("bitmapfont: invalid block size: %d", n)
+	}
+	return dst, nil
+}
+
+// validatePages reports an error if any page of a or its bases is broken.
+// The decoded pages are not kept so that the pages are still decoded lazily.
+func (a *pagedAtlas) validatePages() error {
+	dst := make([]byte, a.pageSize)
+	for atlas := a; atlas != nil; atlas = atlas.base {
+		for i, p := range atlas.pages {
+			if p == nil || len(p) == atlas.pageSize {
+				continue
+			}
+			n, err := lz4.UncompressBlock(p, dst)
+			if err != nil {
+				return fmt.Errorf("bitmapfont: invalid atlas %s: broken page %d: %w", atlas.name, i, err)
+			}
+			if n != atlas.pageSize {
+				return fmt.Errorf("bitmapfont: invalid atlas %s: invalid size of page %d: %d", atlas.name, i, n)
+			}
+		}
+	}
+	return nil
+}
+
+// decodePage returns the bits of the page i.
+// The page is decoded only once, and the bits are shared.
+func (a *pagedAtlas) decodePage(i int) ([]byte, error) {
+	if a.sameAsBase[i] {
+		return a.base.decodePage(i)
+	}
+	p := &a.decoded[i]
+	p.once.Do(func() {
+		if a.pages[i] == nil {
+			return
+		}
+		p.bits, p.err = decodeBlock(a.pages[i], a.pageSize)
+	})
+	return p.bits, p.err
+}
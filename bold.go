@@ -0,0 +1,109 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+var _ font.Face = (*boldFace)(nil)
+
+type boldFace struct {
+	face  font.Face
+	cache glyphCache
+}
+
+// NewBoldFace returns a font.Face of the synthetic bold style of face.
+//
+// Each glyph is overstruck with itself shifted by 1px to the right, and its advance is increased by 1px.
+// A glyph mask is 1px wider than the original glyph region, so fullwidth glyphs are not clipped.
+// The glyph masks are cached, and Glyph doesn't allocate memory for a rune that has already been rendered.
+func NewBoldFace(face font.Face) font.Face {
+	b := &boldFace{
+		face: face,
+	}
+	b.cache = glyphCache{
+		face:     face,
+		generate: b.generateGlyph,
+	}
+	return b
+}
+
+func (b *boldFace) generateGlyph(dr image.Rectangle, mask image.Image, srcMask image.Rectangle, advance fixed.Int26_6) cachedGlyph {
+	return cachedGlyph{
+		dr:      image.Rect(dr.Min.X, dr.Min.Y, dr.Max.X+1, dr.Max.Y),
+		mask:    bitmap.Embolden(mask, srcMask),
+		advance: advance + fixed.I(1),
+		ok:      true,
+	}
+}
+
+func (b *boldFace) Close() error {
+	b.cache.clear()
+	return b.face.Close()
+}
+
+func (b *boldFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	return b.cache.get(r).glyph(dot)
+}
+
+func (b *boldFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	bounds, advance, ok = b.face.GlyphBounds(r)
+	if !ok {
+		return
+	}
+	bounds.Max.X += fixed.I(1)
+	advance += fixed.I(1)
+	return
+}
+
+func (b *boldFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	advance, ok = b.face.GlyphAdvance(r)
+	if !ok {
+		return 0, false
+	}
+	return advance + fixed.I(1), true
+}
+
+func (b *boldFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	k := b.face.Kern(r0, r1)
+	// A nonspacing mark is moved back to overlap the previous glyph.
+	// Cancel the additional advance of the mark too.
+	if isNonspacing(b.face, r1) {
+		k -= fixed.I(1)
+	}
+	return k
+}
+
+func (b *boldFace) Metrics() font.Metrics {
+	return b.face.Metrics()
+}
+
+func (b *boldFace) glyphCoverage() *bitmap.Coverage {
+	return faceCoverage(b.face)
+}
+
+func (b *boldFace) isNonspacing(r rune) bool {
+	return isNonspacing(b.face, r)
+}
+
+func (b *boldFace) underlyingFaces() []font.Face {
+	return []font.Face{b.face}
+}
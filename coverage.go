@@ -0,0 +1,83 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"slices"
+	"unicode"
+
+	"golang.org/x/image/font"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+type coverageFace interface {
+	glyphCoverage() *bitmap.Coverage
+}
+
+func faceCoverage(face font.Face) *bitmap.Coverage {
+	f, ok := face.(coverageFace)
+	if !ok {
+		return nil
+	}
+	return f.glyphCoverage()
+}
+
+// RangeTable returns a table of the runes that face has glyphs for.
+//
+// face must be a font.Face of this package like Face.
+// RangeTable returns nil if face is not a font.Face of this package.
+// RangeTable panics if the data of face is excluded by the build tags in the same way as drawing with face.
+//
+// The returned table is shared and must not be modified.
+func RangeTable(face font.Face) *unicode.RangeTable {
+	c := faceCoverage(face)
+	if c == nil {
+		return nil
+	}
+	return c.RangeTable()
+}
+
+// HasGlyph reports whether face has a glyph for r.
+//
+// For a font.Face of this package, HasGlyph doesn't load the glyph images.
+// For other font.Face values, HasGlyph reports whether face's GlyphAdvance succeeds.
+// HasGlyph panics if the data of face is excluded by the build tags in the same way as drawing with face.
+func HasGlyph(face font.Face, r rune) bool {
+	if c := faceCoverage(face); c != nil {
+		return c.Has(r)
+	}
+	_, ok := face.GlyphAdvance(r)
+	return ok
+}
+
+// Covers returns the runes in s that face doesn't have glyphs for.
+// Each missing rune appears only once in the order of the first appearance.
+// Covers returns nil if face has glyphs for all the runes in s.
+//
+// Note that control characters like '\n' don't have glyphs.
+// Covers panics in the same way as HasGlyph.
+func Covers(face font.Face, s string) (missing []rune) {
+	for _, r := range s {
+		if HasGlyph(face, r) {
+			continue
+		}
+		if slices.Contains(missing, r) {
+			continue
+		}
+		missing = append(missing, r)
+	}
+	return missing
+}
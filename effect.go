@@ -0,0 +1,150 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+// EffectDrawer draws text with an outline and a drop shadow.
+//
+// The outline and the shadow are computed from the whole text rather than from each glyph,
+// so the outline of a glyph never covers its neighbor glyphs even when the glyphs overlap.
+// The shadow is drawn first, the outline next, and the text last.
+//
+// The source images are aligned with the destination image, i.e., the point (x, y) in Dst corresponds to (x, y) in the source images.
+type EffectDrawer struct {
+	// Dst is the destination image.
+	Dst draw.Image
+
+	// Src is the source image for the text.
+	Src image.Image
+
+	// Face is the font face.
+	Face font.Face
+
+	// Dot is the baseline location to draw the next glyph.
+	Dot fixed.Point26_6
+
+	// Outline is the source image for the outline.
+	// If Outline is nil, no outline is drawn.
+	Outline image.Image
+
+	// OutlineWidth is the width of the outline in pixels.
+	// If OutlineWidth is 0, 1 is used.
+	OutlineWidth int
+
+	// Shadow is the source image for the shadow.
+	// If Shadow is nil, no shadow is drawn.
+	//
+	// The shape of the shadow is the text's with the outline if Outline is not nil, or the text's otherwise.
+	Shadow image.Image
+
+	// ShadowOffset is the offset of the shadow from the text in pixels.
+	ShadowOffset image.Point
+}
+
+type effectGlyph struct {
+	dr    image.Rectangle
+	mask  image.Image
+	maskp image.Point
+}
+
+// DrawString draws s at the dot and advances the dot's location.
+func (d *EffectDrawer) DrawString(s string) {
+	var glyphs []effectGlyph
+	var bounds image.Rectangle
+
+	prevC := rune(-1)
+	for _, c := range s {
+		if prevC >= 0 {
+			d.Dot.X += d.Face.Kern(prevC, c)
+		}
+		dr, mask, maskp, advance, ok := d.Face.Glyph(d.Dot, c)
+		if !ok {
+			continue
+		}
+		if !dr.Empty() {
+			glyphs = append(glyphs, effectGlyph{
+				dr:    dr,
+				mask:  mask,
+				maskp: maskp,
+			})
+			bounds = bounds.Union(dr)
+		}
+		d.Dot.X += advance
+		prevC = c
+	}
+	if len(glyphs) == 0 {
+		return
+	}
+
+	if d.Outline != nil || d.Shadow != nil {
+		// Render the whole text as one binary image.
+		w, h := bounds.Dx(), bounds.Dy()
+		text := bitmap.NewBinaryImage(make([]byte, (w*h+7)/8), w, h)
+		for _, g := range glyphs {
+			for j := 0; j < g.dr.Dy(); j++ {
+				for i := 0; i < g.dr.Dx(); i++ {
+					x, y := g.maskp.X+i, g.maskp.Y+j
+					switch mask := g.mask.(type) {
+					case *image.Alpha:
+						if mask.AlphaAt(x, y).A == 0 {
+							continue
+						}
+					case *bitmap.BinaryImage:
+						if !mask.Bit(x, y) {
+							continue
+						}
+					default:
+						if _, _, _, a := mask.At(x, y).RGBA(); a == 0 {
+							continue
+						}
+					}
+					text.SetBit(g.dr.Min.X-bounds.Min.X+i, g.dr.Min.Y-bounds.Min.Y+j)
+				}
+			}
+		}
+
+		shape, shapeBounds := text, bounds
+		var outline *bitmap.BinaryImage
+		if d.Outline != nil {
+			n := d.OutlineWidth
+			if n <= 0 {
+				n = 1
+			}
+			outline = bitmap.Dilate(text, n)
+			shape, shapeBounds = outline, bounds.Inset(-n)
+		}
+
+		if d.Shadow != nil {
+			r := shapeBounds.Add(d.ShadowOffset)
+			draw.DrawMask(d.Dst, r, d.Shadow, r.Min, shape, image.Point{}, draw.Over)
+		}
+		if outline != nil {
+			draw.DrawMask(d.Dst, shapeBounds, d.Outline, shapeBounds.Min, outline, image.Point{}, draw.Over)
+		}
+	}
+
+	for _, g := range glyphs {
+		draw.DrawMask(d.Dst, g.dr, d.Src, g.dr.Min, g.mask, g.maskp, draw.Over)
+	}
+}
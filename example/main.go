@@ -36,11 +36,12 @@ var (
 	flagTest     = flag.Bool("test", false, "test mode")
 	flagTestSC   = flag.Bool("test-sc", false, "test mode (simplified Chinese)")
 	flagTestTC   = flag.Bool("test-tc", false, "test mode (traditional Chinese)")
+	flagTestKO   = flag.Bool("test-ko", false, "test mode (Korean)")
 	flagEastAsia = flag.Bool("eastasia", false, "East Asia")
 )
 
 func isTest() bool {
-	return *flagTest || *flagTestSC || *flagTestTC
+	return *flagTest || *flagTestSC || *flagTestTC || *flagTestKO
 }
 
 func run() error {
@@ -98,6 +99,9 @@ zh-Hant: 人人生而自由，拉尊嚴脫仔權利上一律平等。伊拉有
 		if *flagTestTC {
 			suffix += "_zh_hant"
 		}
+		if *flagTestKO {
+			suffix += "_ko"
+		}
 		if *flagEastAsia {
 			suffix += "_ea"
 		}
@@ -110,22 +114,19 @@ zh-Hant: 人人生而自由，拉尊嚴脫仔權利上一律平等。伊拉有
 }
 
 func defaultFace() font.Face {
-	if *flagTestSC {
-		if *flagEastAsia {
-			return bitmapfont.FaceSCEA
-		}
-		return bitmapfont.FaceSC
-	}
-	if *flagTestTC {
-		if *flagEastAsia {
-			return bitmapfont.FaceTCEA
-		}
-		return bitmapfont.FaceTC
-	}
-	if *flagEastAsia {
-		return bitmapfont.FaceEA
+	var lang language.Tag
+	switch {
+	case *flagTestSC:
+		lang = language.SimplifiedChinese
+	case *flagTestTC:
+		lang = language.TraditionalChinese
+	case *flagTestKO:
+		lang = language.Korean
 	}
-	return bitmapfont.Face
+	return bitmapfont.NewFace(bitmapfont.Options{
+		Language:      lang,
+		EastAsianWide: *flagEastAsia,
+	})
 }
 
 func outputImageFile(text string, path string) error {
@@ -178,29 +179,17 @@ func outputImageFile(text string, path string) error {
 
 	for _, l := range strings.Split(text, "\n") {
 		langstr := langRe.FindString(l)
+		f := defaultFace()
 		if !isTest() && langstr != "" {
 			lang, err := language.Parse(langstr)
 			if err != nil {
 				return err
 			}
 			l = bitmapfont.PresentationForms(l, bitmapfont.DirectionLeftToRight, lang)
-		}
-		f := defaultFace()
-		if !isTest() {
-			if strings.HasPrefix(langstr, "zh-Hans") {
-				if *flagEastAsia {
-					f = bitmapfont.FaceSCEA
-				} else {
-					f = bitmapfont.FaceSC
-				}
-			}
-			if strings.HasPrefix(langstr, "zh-Hant") {
-				if *flagEastAsia {
-					f = bitmapfont.FaceTCEA
-				} else {
-					f = bitmapfont.FaceTC
-				}
-			}
+			f = bitmapfont.NewFace(bitmapfont.Options{
+				Language:      lang,
+				EastAsianWide: *flagEastAsia,
+			})
 		}
 		d.Face = f
 		d.Dot.X = fixed.I(offsetX)
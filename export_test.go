@@ -14,8 +14,33 @@
 
 package bitmapfont
 
+import (
+	"golang.org/x/image/font"
+)
+
 type LazyFace = lazyFace
 
-func NewLazyFace(binFile string, ea bool) *LazyFace {
-	return newDelayedFace(binFile, ea)
+func NewLazyFace(binFile string, size int, ea bool) *LazyFace {
+	return newDelayedFace(binFile, size, ea)
+}
+
+var NewEmbeddedFace = newEmbeddedFace
+
+// Available reports whether face and its underlying faces are not excluded by the build tags.
+func Available(face font.Face) bool {
+	for _, f := range appendDataFaces(nil, face) {
+		if _, ok := f.(*unavailableFace); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// EmbeddedPage returns the decoded bits of the page of the embedded atlas name.
+func EmbeddedPage(name string, page int) ([]byte, error) {
+	a, err := readPagedAtlas(readEmbeddedFile, embeddedAtlases, name)
+	if err != nil {
+		return nil, err
+	}
+	return a.decodePage(page)
 }
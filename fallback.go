@@ -0,0 +1,126 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+var _ font.Face = (*fallbackFace)(nil)
+
+type fallbackFace struct {
+	faces []font.Face
+}
+
+// NewFallbackFace returns a font.Face that renders each rune with the first face in faces that has a glyph for the rune.
+// faces can include any font.Face values, not only the faces of this package.
+//
+// All the glyphs share the same baseline.
+// The ascent and the descent of the returned face's Metrics are the maximum values among faces,
+// so that a line height based on the Metrics can contain any glyph.
+//
+// Kern returns a non-zero value only when both runes are rendered with the same face.
+//
+// Close closes all the faces.
+func NewFallbackFace(faces ...font.Face) font.Face {
+	return &fallbackFace{
+		faces: append([]font.Face(nil), faces...),
+	}
+}
+
+// faceFor returns the first face that has a glyph for r.
+// faceFor returns nil if no face has a glyph for r.
+func (f *fallbackFace) faceFor(r rune) font.Face {
+	for _, face := range f.faces {
+		if HasGlyph(face, r) {
+			return face
+		}
+	}
+	return nil
+}
+
+func (f *fallbackFace) Close() error {
+	var err error
+	for _, face := range f.faces {
+		if e := face.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	face := f.faceFor(r)
+	if face == nil {
+		return
+	}
+	return face.Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	face := f.faceFor(r)
+	if face == nil {
+		return
+	}
+	return face.GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	face := f.faceFor(r)
+	if face == nil {
+		return 0, false
+	}
+	return face.GlyphAdvance(r)
+}
+
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.faceFor(r0)
+	if face == nil || face != f.faceFor(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	if len(f.faces) == 0 {
+		return font.Metrics{}
+	}
+
+	// Use the primary face's metrics for the values other than the vertical extents.
+	m := f.faces[0].Metrics()
+	for _, face := range f.faces[1:] {
+		fm := face.Metrics()
+		m.Ascent = max(m.Ascent, fm.Ascent)
+		m.Descent = max(m.Descent, fm.Descent)
+		m.Height = max(m.Height, fm.Height)
+	}
+	m.Height = max(m.Height, m.Ascent+m.Descent)
+	return m
+}
+
+func (f *fallbackFace) isNonspacing(r rune) bool {
+	face := f.faceFor(r)
+	if face == nil {
+		return false
+	}
+	return isNonspacing(face, r)
+}
+
+func (f *fallbackFace) underlyingFaces() []font.Face {
+	return f.faces
+}
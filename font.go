@@ -20,8 +20,8 @@ import (
 )
 
 func init() {
-	Face = newDelayedFace("data/face_ja.bin", false)
-	FaceEA = newDelayedFace("data/face_ja_ea.bin", true)
+	Face = newEmbeddedFace("Face", "data/face_ja.bin", 12, false, jaAvailable, "bitmapfont_noja")
+	FaceEA = newEmbeddedFace("FaceEA", "data/face_ja_ea.bin", 12, true, jaAvailable && eaAvailable, "bitmapfont_noja", "bitmapfont_noea")
 }
 
 var (
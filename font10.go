@@ -0,0 +1,34 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"golang.org/x/image/font"
+)
+
+// There are no 10px variants preferring Chinese characters, as Cubic 11 and Ark Pixel Font have only 12px glyphs.
+
+func init() {
+	Face10 = newEmbeddedFace("Face10", "data/face10_ja.bin", 10, false, face10Available, "bitmapfont_noja", "bitmapfont_no10")
+	Face10EA = newEmbeddedFace("Face10EA", "data/face10_ja_ea.bin", 10, true, face10Available && eaAvailable, "bitmapfont_noja", "bitmapfont_no10", "bitmapfont_noea")
+}
+
+var (
+	// Face10 is a font.Face of the bitmap font (10px regular).
+	Face10 font.Face
+
+	// Face10EA is a font.Face of the bitmap font (10px regular, prefer East Asian wide characters).
+	Face10EA font.Face
+)
@@ -15,24 +15,190 @@
 package bitmapfont_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"unicode"
 
 	"github.com/hajimehoshi/bitmapfont/v4"
+	"github.com/hajimehoshi/bitmapfont/v4/testdata/subset"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/language"
 )
 
+// skipIfUnavailable skips the test if any of faces is excluded by the build tags.
+func skipIfUnavailable(tb testing.TB, faces ...font.Face) {
+	tb.Helper()
+	for _, f := range faces {
+		if !bitmapfont.Available(f) {
+			tb.Skip(bitmapfont.Err(f))
+		}
+	}
+}
+
+// availableFaces returns faces except for the ones excluded by the build tags.
+func availableFaces(faces ...font.Face) []font.Face {
+	return slices.DeleteFunc(faces, func(f font.Face) bool {
+		return !bitmapfont.Available(f)
+	})
+}
+
 func BenchmarkLazyFace(b *testing.B) {
+	skipIfUnavailable(b, bitmapfont.Face)
+
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		l := bitmapfont.NewLazyFace("data/face_ja.bin", false)
+		l := bitmapfont.NewLazyFace("data/face_ja.bin", 12, false)
 		if _, _, _, _, ok := l.Glyph(fixed.P(0, 0), 'あ'); !ok {
 			b.Fatal("Glyph failed")
 		}
 	}
 }
 
+func BenchmarkGlyph(b *testing.B) {
+	skipIfUnavailable(b, bitmapfont.Face)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, r := range "Hello, 世界!" {
+			if _, _, _, _, ok := bitmapfont.Face.Glyph(fixed.P(0, 0), r); !ok {
+				b.Fatal("Glyph failed")
+			}
+		}
+	}
+}
+
+func BenchmarkDrawString(b *testing.B) {
+	skipIfUnavailable(b, bitmapfont.Face)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 256, 16))
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.Black,
+		Face: bitmapfont.Face,
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.Dot = fixed.P(0, 12)
+		d.DrawString("Hello, 世界!")
+	}
+}
+
+func BenchmarkMeasureString(b *testing.B) {
+	skipIfUnavailable(b, bitmapfont.Face)
+
+	s := strings.Repeat("Hello, 世界! Ça va? ", 16)
+	for _, tc := range []struct {
+		name string
+		face font.Face
+	}{
+		{"regular", bitmapfont.Face},
+		{"proportional", bitmapfont.NewProportionalFace(bitmapfont.Face)},
+	} {
+		// font.MeasureString is the baseline calling the font.Face methods for each rune.
+		b.Run(tc.name+"/font", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				font.MeasureString(tc.face, s)
+			}
+		})
+		b.Run(tc.name+"/bitmapfont", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bitmapfont.MeasureString(tc.face, s)
+			}
+		})
+	}
+}
+
+func TestLazyFaceClose(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	l := bitmapfont.NewLazyFace("data/face_ja.bin", 12, false)
+	p := bitmapfont.NewProportionalFace(l)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, _, _, _, ok := l.Glyph(fixed.P(0, 0), 'あ'); !ok {
+					t.Error("Glyph failed")
+					return
+				}
+				if _, ok := p.GlyphAdvance('a'); !ok {
+					t.Error("GlyphAdvance failed")
+					return
+				}
+				if j%10 == 0 {
+					if err := l.Close(); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The face must be available after Close.
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := font.MeasureString(l, "aあ"), fixed.I(6+12); got != want {
+		t.Errorf("width after Close: got: %v, want: %v", got, want)
+	}
+}
+
+func TestSharedBase(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face, bitmapfont.FaceKOEA)
+
+	// Use the faces so that their atlases are alive.
+	bitmapfont.Face.Glyph(fixed.P(0, 0), 'a')
+	bitmapfont.FaceKOEA.Glyph(fixed.P(0, 0), 'a')
+
+	page := func(name string, page int) []byte {
+		t.Helper()
+		bits, err := bitmapfont.EmbeddedPage(name, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bits
+	}
+
+	// The pages that are the same as the base's must be decoded only once and shared.
+	for _, name := range []string{"data/face_ko.bin", "data/face_ko_ea.bin"} {
+		if got, want := &page(name, 0)[0], &page("data/face_ja.bin", 0)[0]; got != want {
+			t.Errorf("page 0 of %s must be shared with face_ja.bin", name)
+		}
+	}
+	if got, want := &page("data/face_ko_ea.bin", 0x4e)[0], &page("data/face_ko.bin", 0x4e)[0]; got != want {
+		t.Errorf("page 0x4e of face_ko_ea.bin must be shared with face_ko.bin")
+	}
+	// Hanja come from a Korean font, so the page is not shared with face_ja.bin.
+	if got, want := &page("data/face_ko.bin", 0x4e)[0], &page("data/face_ja.bin", 0x4e)[0]; got == want {
+		t.Errorf("page 0x4e of face_ko.bin must not be shared with face_ja.bin")
+	}
+}
+
 func TestWidth(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
 	testCaeses := []struct {
 		str string
 		w   fixed.Int26_6
@@ -73,3 +239,946 @@ func TestWidth(t *testing.T) {
 		}
 	}
 }
+
+func TestWidth10(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face10)
+
+	testCaeses := []struct {
+		str string
+		w   fixed.Int26_6
+	}{
+		{
+			str: "a",
+			w:   fixed.I(5),
+		},
+		{
+			str: "あ",
+			w:   fixed.I(10),
+		},
+		{
+			str: "ｱ",
+			w:   fixed.I(5),
+		},
+	}
+	for _, tc := range testCaeses {
+		advance := font.MeasureString(bitmapfont.Face10, tc.str)
+		if got, want := advance, tc.w; got != want {
+			t.Errorf("width for %q: got: %v, want: %v", tc.str, got, want)
+		}
+	}
+	if got, want := bitmapfont.Face10.Metrics().Height, fixed.I(13); got != want {
+		t.Errorf("height: got: %v, want: %v", got, want)
+	}
+}
+
+func TestSupplementaryPlane(t *testing.T) {
+	// U+20086 is a CJK Unified Ideograph in Extension B.
+	const r = '\U00020086'
+	for _, f := range availableFaces(bitmapfont.Face, bitmapfont.FaceSC, bitmapfont.FaceTC, bitmapfont.FaceKO) {
+		dr, mask, maskp, advance, ok := f.Glyph(fixed.P(0, 12), r)
+		if !ok {
+			t.Fatalf("Glyph(%U) failed", r)
+		}
+		if got, want := advance, fixed.I(12); got != want {
+			t.Errorf("advance for %U: got: %v, want: %v", r, got, want)
+		}
+		var found bool
+		for j := 0; j < dr.Dy() && !found; j++ {
+			for i := 0; i < dr.Dx() && !found; i++ {
+				if _, _, _, a := mask.At(maskp.X+i, maskp.Y+j).RGBA(); a != 0 {
+					found = true
+				}
+			}
+		}
+		if !found {
+			t.Errorf("glyph for %U is empty", r)
+		}
+	}
+
+	// The 10px face doesn't have any glyphs in the supplementary planes.
+	skipIfUnavailable(t, bitmapfont.Face10)
+	if _, ok := bitmapfont.Face10.GlyphAdvance(r); ok {
+		t.Errorf("GlyphAdvance(%U) for Face10 must fail", r)
+	}
+}
+
+func TestMissingGlyph(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	// U+0378 is unassigned.
+	const r = '͸'
+	if _, _, _, _, ok := bitmapfont.Face.Glyph(fixed.P(0, 12), r); ok {
+		t.Errorf("Glyph(%U) must fail", r)
+	}
+	if _, _, ok := bitmapfont.Face.GlyphBounds(r); ok {
+		t.Errorf("GlyphBounds(%U) must fail", r)
+	}
+	if _, ok := bitmapfont.Face.GlyphAdvance(r); ok {
+		t.Errorf("GlyphAdvance(%U) must fail", r)
+	}
+
+	// A space is not a missing glyph.
+	if a, ok := bitmapfont.Face.GlyphAdvance(' '); !ok || a != fixed.I(6) {
+		t.Errorf("GlyphAdvance(' '): got: %v, %t, want: %v, true", a, ok, fixed.I(6))
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	for _, f := range availableFaces(bitmapfont.Face, bitmapfont.FaceSC, bitmapfont.FaceTCEA, bitmapfont.Face10) {
+		if !bitmapfont.HasGlyph(f, 'a') {
+			t.Errorf("HasGlyph('a') must be true")
+		}
+		if bitmapfont.HasGlyph(f, '͸') {
+			t.Errorf("HasGlyph(U+0378) must be false")
+		}
+		table := bitmapfont.RangeTable(f)
+		for _, r := range []rune{'a', 'あ', '中', '\U00020086'} {
+			_, ok := f.GlyphAdvance(r)
+			if got, want := unicode.Is(table, r), ok; got != want {
+				t.Errorf("unicode.Is(RangeTable(f), %U): got: %t, want: %t", r, got, want)
+			}
+		}
+	}
+
+	skipIfUnavailable(t, bitmapfont.Face)
+	if got, want := bitmapfont.Covers(bitmapfont.Face, "a͸b͸c\U0001F000"), []rune{'͸', '\U0001F000'}; !slices.Equal(got, want) {
+		t.Errorf("Covers: got: %q, want: %q", got, want)
+	}
+	if got := bitmapfont.Covers(bitmapfont.Face, "Hello, 世界"); got != nil {
+		t.Errorf("Covers: got: %q, want: nil", got)
+	}
+}
+
+func TestFallbackFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face, bitmapfont.Face10)
+
+	f := bitmapfont.NewFallbackFace(bitmapfont.Face10, bitmapfont.Face)
+
+	// 'あ' is in Face10, and U+20086 is only in Face.
+	if got, want := font.MeasureString(f, "あ\U00020086"), fixed.I(10+12); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+	if _, ok := f.GlyphAdvance('͸'); ok {
+		t.Errorf("GlyphAdvance(U+0378) must fail")
+	}
+
+	m := f.Metrics()
+	if got, want := m.Ascent, bitmapfont.Face.Metrics().Ascent; got != want {
+		t.Errorf("ascent: got: %v, want: %v", got, want)
+	}
+	if got, want := m.Height, bitmapfont.Face.Metrics().Height; got != want {
+		t.Errorf("height: got: %v, want: %v", got, want)
+	}
+}
+
+func TestScaledFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	const scale = 3
+	f := bitmapfont.NewScaledFace(bitmapfont.Face, scale)
+
+	if got, want := font.MeasureString(f, "aあ"), fixed.I((6+12)*scale); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+	if got, want := f.Metrics().Height, bitmapfont.Face.Metrics().Height*scale; got != want {
+		t.Errorf("height: got: %v, want: %v", got, want)
+	}
+
+	dr0, mask0, maskp0, _, _ := bitmapfont.Face.Glyph(fixed.P(0, 0), 'あ')
+	dr, mask, maskp, _, ok := f.Glyph(fixed.P(0, 0), 'あ')
+	if !ok {
+		t.Fatal("Glyph failed")
+	}
+	if got, want := dr, image.Rect(dr0.Min.X*scale, dr0.Min.Y*scale, dr0.Max.X*scale, dr0.Max.Y*scale); got != want {
+		t.Errorf("dr: got: %v, want: %v", got, want)
+	}
+	for j := 0; j < dr.Dy(); j++ {
+		for i := 0; i < dr.Dx(); i++ {
+			_, _, _, got := mask.At(maskp.X+i, maskp.Y+j).RGBA()
+			_, _, _, want := mask0.At(maskp0.X+i/scale, maskp0.Y+j/scale).RGBA()
+			if got != want {
+				t.Fatalf("mask.At(%d, %d): got: %d, want: %d", i, j, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkScaledFace(b *testing.B) {
+	skipIfUnavailable(b, bitmapfont.Face)
+
+	f := bitmapfont.NewScaledFace(bitmapfont.Face, 2)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, _, ok := f.Glyph(fixed.P(0, 0), 'あ'); !ok {
+			b.Fatal("Glyph failed")
+		}
+	}
+}
+
+func TestBoldFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	f := bitmapfont.NewBoldFace(bitmapfont.Face)
+
+	if got, want := font.MeasureString(f, "aあ"), fixed.I(6+1+12+1); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+	// U+0301 is a nonspacing mark.
+	if got, want := font.MeasureString(f, "a\u0301"), fixed.I(6+1); got != want {
+		t.Errorf("width with a nonspacing mark: got: %v, want: %v", got, want)
+	}
+	for _, f := range []font.Face{
+		bitmapfont.NewBoldFace(bitmapfont.NewProportionalFace(bitmapfont.Face)),
+		bitmapfont.NewBoldFace(bitmapfont.NewScaledFace(bitmapfont.Face, 2)),
+	} {
+		a := font.MeasureString(f, "a")
+		if got, want := font.MeasureString(f, "a\u0301"), a; got != want {
+			t.Errorf("width with a nonspacing mark: got: %v, want: %v", got, want)
+		}
+	}
+
+	// '█' fills its whole region. The bold glyph must not be clipped nor bleed from the neighbors.
+	for _, r := range []rune{'█', '国'} {
+		dr0, mask0, maskp0, _, _ := bitmapfont.Face.Glyph(fixed.P(0, 0), r)
+		dr, mask, maskp, _, ok := f.Glyph(fixed.P(0, 0), r)
+		if !ok {
+			t.Fatal("Glyph failed")
+		}
+		if got, want := dr.Dx(), dr0.Dx()+1; got != want {
+			t.Errorf("width of %q: got: %d, want: %d", r, got, want)
+		}
+		for j := 0; j < dr.Dy(); j++ {
+			for i := 0; i < dr.Dx(); i++ {
+				var want uint32
+				for _, x := range []int{i - 1, i} {
+					if x < 0 || x >= dr0.Dx() {
+						continue
+					}
+					if _, _, _, a := mask0.At(maskp0.X+x, maskp0.Y+j).RGBA(); a != 0 {
+						want = a
+					}
+				}
+				if _, _, _, got := mask.At(maskp.X+i, maskp.Y+j).RGBA(); got != want {
+					t.Fatalf("mask.At(%d, %d) of %q: got: %d, want: %d", i, j, r, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestObliqueFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	f := bitmapfont.NewObliqueFace(bitmapfont.Face)
+
+	if got, want := font.MeasureString(f, "aあ"), fixed.I(6+12); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+	if got, want := f.Metrics().CaretSlope, image.Pt(1, 4); got != want {
+		t.Errorf("caret slope: got: %v, want: %v", got, want)
+	}
+
+	// The row right above the baseline is not shifted. The top row is shifted by 2px.
+	shiftAt := func(y int) int {
+		switch {
+		case y < -8:
+			return 2
+		case y < -4:
+			return 1
+		case y < 0:
+			return 0
+		default:
+			return -1
+		}
+	}
+
+	for _, r := range []rune{'l', '国'} {
+		dr0, mask0, maskp0, _, _ := bitmapfont.Face.Glyph(fixed.P(0, 0), r)
+		dr, mask, maskp, _, ok := f.Glyph(fixed.P(0, 0), r)
+		if !ok {
+			t.Fatal("Glyph failed")
+		}
+		for j := 0; j < dr0.Dy(); j++ {
+			shift := shiftAt(dr0.Min.Y + j)
+			for i := 0; i < dr0.Dx(); i++ {
+				_, _, _, want := mask0.At(maskp0.X+i, maskp0.Y+j).RGBA()
+				x := i + shift - (dr.Min.X - dr0.Min.X)
+				_, _, _, got := mask.At(maskp.X+x, maskp.Y+j).RGBA()
+				if got != want {
+					t.Fatalf("pixel (%d, %d) of %q: got: %d, want: %d", i, j, r, got, want)
+				}
+			}
+		}
+
+		b0, _, _ := bitmapfont.Face.GlyphBounds(r)
+		b, _, _ := f.GlyphBounds(r)
+		if got, want := b.Min.X, b0.Min.X+fixed.I(shiftAt(b0.Max.Y.Ceil()-1)); got != want {
+			t.Errorf("bounds.Min.X of %q: got: %v, want: %v", r, got, want)
+		}
+		if got, want := b.Max.X, b0.Max.X+fixed.I(shiftAt(b0.Min.Y.Floor())); got != want {
+			t.Errorf("bounds.Max.X of %q: got: %v, want: %v", r, got, want)
+		}
+	}
+
+	// A space has no ink, and its bounds are not shifted.
+	b0, a0, _ := bitmapfont.Face.GlyphBounds(' ')
+	b, a, ok := f.GlyphBounds(' ')
+	if !ok {
+		t.Fatal("GlyphBounds(' ') failed")
+	}
+	if b != b0 || a != a0 {
+		t.Errorf("GlyphBounds(' '): got: %v, %v, want: %v, %v", b, a, b0, a0)
+	}
+	if b.Min.X > b.Max.X || b.Min.Y > b.Max.Y {
+		t.Errorf("GlyphBounds(' '): invalid bounds: %v", b)
+	}
+}
+
+func TestEffectDrawer(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 32, 24))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	d := bitmapfont.EffectDrawer{
+		Dst:     dst,
+		Src:     image.NewUniform(red),
+		Face:    bitmapfont.Face,
+		Dot:     fixed.P(4, 16),
+		Outline: image.Black,
+	}
+	d.DrawString("ll")
+	if got, want := d.Dot, fixed.P(4+12, 16); got != want {
+		t.Errorf("dot: got: %v, want: %v", got, want)
+	}
+
+	// A missing glyph neither advances the dot nor breaks kerning.
+	for _, f := range []font.Face{bitmapfont.Face, bitmapfont.NewProportionalFace(bitmapfont.Face)} {
+		const s = "T\u0378o"
+		d := bitmapfont.EffectDrawer{
+			Dst:  image.NewRGBA(image.Rect(0, 0, 32, 24)),
+			Src:  image.Black,
+			Face: f,
+		}
+		d.DrawString(s)
+		if got, want := d.Dot.X, bitmapfont.MeasureString(f, s); got != want {
+			t.Errorf("dot with a missing glyph: got: %v, want: %v", got, want)
+		}
+	}
+
+	// Render the text without effects to get the expected shape.
+	ink := image.NewAlpha(dst.Bounds())
+	(&font.Drawer{Dst: ink, Src: image.Opaque, Face: bitmapfont.Face, Dot: fixed.P(4, 16)}).DrawString("ll")
+	isInk := func(x, y int) bool {
+		return ink.AlphaAt(x, y).A != 0
+	}
+
+	for j := 0; j < dst.Bounds().Dy(); j++ {
+		for i := 0; i < dst.Bounds().Dx(); i++ {
+			want := color.RGBA{0xff, 0xff, 0xff, 0xff}
+			if isInk(i, j) {
+				want = red
+			} else {
+			outer:
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if isInk(i+dx, j+dy) {
+							want = color.RGBA{0, 0, 0, 0xff}
+							break outer
+						}
+					}
+				}
+			}
+			if got := dst.RGBAAt(i, j); got != want {
+				t.Fatalf("pixel (%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestProportionalFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	f := bitmapfont.NewProportionalFace(bitmapfont.Face)
+
+	iAdv, _ := f.GlyphAdvance('i')
+	mAdv, _ := f.GlyphAdvance('m')
+	if iAdv >= mAdv {
+		t.Errorf("advance of 'i' (%v) must be less than 'm' (%v)", iAdv, mAdv)
+	}
+	if got, want := font.MeasureString(f, "あ漢"), fixed.I(24); got != want {
+		t.Errorf("width of CJK glyphs: got: %v, want: %v", got, want)
+	}
+	tAdv, _ := f.GlyphAdvance('T')
+	eAdv, _ := f.GlyphAdvance('e')
+	if got, want := font.MeasureString(f, "Te"), tAdv+eAdv-fixed.I(1); got != want {
+		t.Errorf("width of \"Te\": got: %v, want: %v", got, want)
+	}
+
+	// The glyphs must be the same as the original ones except for their horizontal positions.
+	for _, r := range "AiW.あ" {
+		dr0, mask0, maskp0, _, _ := bitmapfont.Face.Glyph(fixed.P(0, 0), r)
+		dr, mask, maskp, _, ok := f.Glyph(fixed.P(0, 0), r)
+		if !ok {
+			t.Fatal("Glyph failed")
+		}
+		left := dr0.Dx()
+		for j := 0; j < dr0.Dy(); j++ {
+			for i := 0; i < left; i++ {
+				if _, _, _, a := mask0.At(maskp0.X+i, maskp0.Y+j).RGBA(); a != 0 {
+					left = i
+					break
+				}
+			}
+		}
+		left = min(left, dr0.Dx()-dr.Dx())
+		for j := 0; j < dr0.Dy(); j++ {
+			for i := 0; i < dr0.Dx(); i++ {
+				_, _, _, want := mask0.At(maskp0.X+i, maskp0.Y+j).RGBA()
+				var got uint32
+				if x := i - left; x >= 0 && x < dr.Dx() {
+					_, _, _, got = mask.At(maskp.X+x, maskp.Y+j).RGBA()
+				}
+				if got != want {
+					t.Fatalf("mask.At(%d, %d) of %q: got: %d, want: %d", i, j, r, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestLayoutFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	f := bitmapfont.WithLayout(bitmapfont.Face, bitmapfont.LayoutOptions{
+		LineHeight:    20,
+		LetterSpacing: 2,
+		BaselineShift: 1,
+	})
+
+	m0 := bitmapfont.Face.Metrics()
+	m := f.Metrics()
+	if got, want := m.Height, fixed.I(20); got != want {
+		t.Errorf("Height: got: %v, want: %v", got, want)
+	}
+	if got, want := m.Ascent+m.Descent, m.Height; got != want {
+		t.Errorf("Ascent+Descent: got: %v, want: %v", got, want)
+	}
+	if got, want := m.Ascent, m0.Ascent+fixed.I(1+2); got != want {
+		t.Errorf("Ascent: got: %v, want: %v", got, want)
+	}
+
+	if got, want := font.MeasureString(f, "aあ"), fixed.I(6+2+12+2); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+	if got, want := font.MeasureString(f, "a\u0301"), fixed.I(6+2); got != want {
+		t.Errorf("width with a nonspacing mark: got: %v, want: %v", got, want)
+	}
+	// The letter spacing is not added to a nonspacing mark even with other wrappers.
+	f2 := bitmapfont.WithLayout(bitmapfont.NewBoldFace(bitmapfont.Face), bitmapfont.LayoutOptions{LetterSpacing: 2})
+	if got, want := font.MeasureString(f2, "a\u0301"), fixed.I(6+1+2); got != want {
+		t.Errorf("width with a nonspacing mark: got: %v, want: %v", got, want)
+	}
+
+	dr0, _, _, _, _ := bitmapfont.Face.Glyph(fixed.P(0, 0), 'a')
+	dr, _, _, advance, _ := f.Glyph(fixed.P(0, 0), 'a')
+	if got, want := dr, dr0.Add(image.Pt(0, -1)); got != want {
+		t.Errorf("dr: got: %v, want: %v", got, want)
+	}
+	if got, want := advance, fixed.I(6+2); got != want {
+		t.Errorf("advance: got: %v, want: %v", got, want)
+	}
+	bounds0, _, _ := bitmapfont.Face.GlyphBounds('a')
+	bounds, _, _ := f.GlyphBounds('a')
+	if got, want := bounds.Min.Y, bounds0.Min.Y-fixed.I(1); got != want {
+		t.Errorf("bounds.Min.Y: got: %v, want: %v", got, want)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	m := bitmapfont.Face.Metrics()
+	if got, want := m.CapHeight, fixed.I(9); got != want {
+		t.Errorf("CapHeight: got: %v, want: %v", got, want)
+	}
+	if got, want := m.XHeight, fixed.I(6); got != want {
+		t.Errorf("XHeight: got: %v, want: %v", got, want)
+	}
+	if got, want := m.CaretSlope, image.Pt(0, 1); got != want {
+		t.Errorf("CaretSlope: got: %v, want: %v", got, want)
+	}
+
+	if b, _, _ := bitmapfont.Face.GlyphBounds(' '); !b.Empty() {
+		t.Errorf("bounds of ' ' must be empty but not: %v", b)
+	}
+
+	// The bounds must be the ink box of the glyph.
+	for _, f := range availableFaces(bitmapfont.Face, bitmapfont.FaceTC) {
+		for _, r := range "gH.国。" {
+			dr, mask, maskp, _, _ := f.Glyph(fixed.P(0, 0), r)
+			var ink image.Rectangle
+			for j := 0; j < dr.Dy(); j++ {
+				for i := 0; i < dr.Dx(); i++ {
+					if _, _, _, a := mask.At(maskp.X+i, maskp.Y+j).RGBA(); a != 0 {
+						ink = ink.Union(image.Rect(i, j, i+1, j+1).Add(dr.Min))
+					}
+				}
+			}
+			b, _, _ := f.GlyphBounds(r)
+			if got, want := b, fixed.R(ink.Min.X, ink.Min.Y, ink.Max.X, ink.Max.Y); got != want {
+				t.Errorf("bounds of %q: got: %v, want: %v", r, got, want)
+			}
+		}
+	}
+}
+
+func TestFaceKO(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face, bitmapfont.FaceKO, bitmapfont.FaceKOEA)
+
+	if got, want := font.MeasureString(bitmapfont.FaceKO, "가漢a"), fixed.I(12+12+6); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+	if got, want := font.MeasureString(bitmapfont.FaceKOEA, "※"), fixed.I(12); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+
+	// Hanja come from a Korean font, not from the Japanese one.
+	const r = '漢'
+	_, mask0, maskp0, _, _ := bitmapfont.Face.Glyph(fixed.P(0, 0), r)
+	dr, mask, maskp, _, ok := bitmapfont.FaceKO.Glyph(fixed.P(0, 0), r)
+	if !ok {
+		t.Fatalf("Glyph(%q) failed", r)
+	}
+	var diff bool
+	for j := 0; j < dr.Dy() && !diff; j++ {
+		for i := 0; i < dr.Dx() && !diff; i++ {
+			_, _, _, a0 := mask0.At(maskp0.X+i, maskp0.Y+j).RGBA()
+			_, _, _, a := mask.At(maskp.X+i, maskp.Y+j).RGBA()
+			diff = a0 != a
+		}
+	}
+	if !diff {
+		t.Errorf("glyph for %q must be different from Face's", r)
+	}
+}
+
+func TestNewFace(t *testing.T) {
+	testCases := []struct {
+		options bitmapfont.Options
+		face    font.Face
+	}{
+		{
+			options: bitmapfont.Options{},
+			face:    bitmapfont.Face,
+		},
+		{
+			options: bitmapfont.Options{Language: language.English, EastAsianWide: true},
+			face:    bitmapfont.FaceEA,
+		},
+		{
+			options: bitmapfont.Options{Language: language.MustParse("zh-TW")},
+			face:    bitmapfont.FaceTC,
+		},
+		{
+			options: bitmapfont.Options{Language: language.MustParse("zh-HK"), EastAsianWide: true},
+			face:    bitmapfont.FaceTCEA,
+		},
+		{
+			options: bitmapfont.Options{Language: language.MustParse("zh")},
+			face:    bitmapfont.FaceSC,
+		},
+		{
+			options: bitmapfont.Options{Language: language.MustParse("zh-SG")},
+			face:    bitmapfont.FaceSC,
+		},
+		{
+			options: bitmapfont.Options{Language: language.MustParse("ko-KR")},
+			face:    bitmapfont.FaceKO,
+		},
+		{
+			options: bitmapfont.Options{Language: language.Korean, Size: 10},
+			face:    bitmapfont.Face10,
+		},
+	}
+	for _, tc := range testCases {
+		if got, want := bitmapfont.NewFace(tc.options), tc.face; got != want {
+			t.Errorf("NewFace(%v): got: %v, want: %v", tc.options, got, want)
+		}
+	}
+
+	skipIfUnavailable(t, bitmapfont.Face)
+	f := bitmapfont.NewFace(bitmapfont.Options{Language: language.Japanese, Scale: 2})
+	if got, want := font.MeasureString(f, "aあ"), fixed.I(2*(6+12)); got != want {
+		t.Errorf("width: got: %v, want: %v", got, want)
+	}
+
+	// The scaled face is shared so that its glyph cache is used across the calls.
+	if got := bitmapfont.NewFace(bitmapfont.Options{Scale: 2}); got != f {
+		t.Errorf("NewFace with the same options must return the same face: got: %p, want: %p", got, f)
+	}
+	if got := bitmapfont.NewFace(bitmapfont.Options{Scale: 3}); got == f {
+		t.Error("NewFace with a different scale must return a different face")
+	}
+}
+
+func TestUnavailableFace(t *testing.T) {
+	// A face whose data is not embedded must panic with the build tag.
+	f := bitmapfont.NewEmbeddedFace("FaceXX", "data/face_xx.bin", 12, false, false, "bitmapfont_noxx")
+	for _, tc := range []struct {
+		name string
+		f    func()
+	}{
+		{"GlyphAdvance", func() { f.GlyphAdvance('a') }},
+		{"MeasureString", func() { bitmapfont.MeasureString(f, "a") }},
+		{"RangeTable", func() { bitmapfont.RangeTable(f) }},
+		{"RangeTable with a scaled face", func() { bitmapfont.RangeTable(bitmapfont.NewScaledFace(f, 2)) }},
+		{"Glyphs", func() {
+			for range bitmapfont.Glyphs(f) {
+			}
+		}},
+		{"HasGlyph", func() { bitmapfont.HasGlyph(f, 'a') }},
+	} {
+		func() {
+			defer func() {
+				err, ok := recover().(error)
+				if !ok {
+					t.Fatalf("%s must panic with an error", tc.name)
+				}
+				if !strings.Contains(err.Error(), "bitmapfont_noxx") {
+					t.Errorf("%s: the error must include the build tag: %v", tc.name, err)
+				}
+			}()
+			tc.f()
+		}()
+	}
+}
+
+func TestBuildTags(t *testing.T) {
+	var tags []string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "-tags" {
+				tags = strings.Split(s.Value, ",")
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		face font.Face
+		tags []string
+	}{
+		{"Face", bitmapfont.Face, []string{"bitmapfont_noja"}},
+		{"FaceEA", bitmapfont.FaceEA, []string{"bitmapfont_noja", "bitmapfont_noea"}},
+		{"Face10", bitmapfont.Face10, []string{"bitmapfont_noja", "bitmapfont_no10"}},
+		{"Face10EA", bitmapfont.Face10EA, []string{"bitmapfont_noja", "bitmapfont_no10", "bitmapfont_noea"}},
+		{"FaceSC", bitmapfont.FaceSC, []string{"bitmapfont_nosc"}},
+		{"FaceSCEA", bitmapfont.FaceSCEA, []string{"bitmapfont_nosc", "bitmapfont_noea"}},
+		{"FaceTC", bitmapfont.FaceTC, []string{"bitmapfont_notc"}},
+		{"FaceTCEA", bitmapfont.FaceTCEA, []string{"bitmapfont_notc", "bitmapfont_noea"}},
+		{"FaceKO", bitmapfont.FaceKO, []string{"bitmapfont_noko"}},
+		{"FaceKOEA", bitmapfont.FaceKOEA, []string{"bitmapfont_noko", "bitmapfont_noea"}},
+	} {
+		excluded := slices.ContainsFunc(tc.tags, func(tag string) bool {
+			return slices.Contains(tags, tag)
+		})
+		if got, want := bitmapfont.Available(tc.face), !excluded; got != want {
+			t.Errorf("%s: available with the build tags %q: got: %t, want: %t", tc.name, tags, got, want)
+		}
+		err := bitmapfont.Err(tc.face)
+		if excluded && err == nil {
+			t.Errorf("%s: Err with the build tags %q must return an error", tc.name, tags)
+		}
+		if !excluded && err != nil {
+			t.Errorf("%s: Err with the build tags %q: %v", tc.name, tags, err)
+		}
+	}
+}
+
+func TestSubsetFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face)
+
+	// subset.Face is generated from testdata/subset.txt by go generate.
+	text, err := os.ReadFile("testdata/subset.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runes := map[rune]struct{}{}
+	for _, r := range string(text) {
+		want, ok := bitmapfont.GlyphBitmap(bitmapfont.Face, r)
+		if !ok {
+			continue
+		}
+		runes[r] = struct{}{}
+		got, ok := bitmapfont.GlyphBitmap(subset.Face, r)
+		if !ok {
+			t.Errorf("GlyphBitmap(%U) for the subset face failed", r)
+			continue
+		}
+		if got.Advance != want.Advance || !bytes.Equal(got.Alpha().Pix, want.Alpha().Pix) {
+			t.Errorf("glyph for %U doesn't match", r)
+		}
+	}
+	for r := range bitmapfont.Glyphs(subset.Face) {
+		if _, ok := runes[r]; !ok {
+			t.Errorf("the subset face must not have a glyph for %U", r)
+		}
+	}
+	for _, r := range "xあ\U00020087" {
+		if _, ok := subset.Face.GlyphAdvance(r); ok {
+			t.Errorf("GlyphAdvance(%U) for the subset face must fail", r)
+		}
+	}
+
+	// The size and East Asian wide are read from the atlas.
+	skipIfUnavailable(t, bitmapfont.FaceEA)
+	ea := bitmapfont.NewSubsetFace(os.DirFS("data"), "face_ja_ea.bin")
+	if got, want := font.MeasureString(ea, "α"), font.MeasureString(bitmapfont.FaceEA, "α"); got != want {
+		t.Errorf("width for %q: got: %v, want: %v", "α", got, want)
+	}
+}
+
+func TestMeasureString(t *testing.T) {
+	faces := availableFaces(
+		bitmapfont.Face,
+		bitmapfont.FaceEA,
+		bitmapfont.FaceTC,
+		bitmapfont.Face10,
+		bitmapfont.NewProportionalFace(bitmapfont.Face),
+		bitmapfont.NewProportionalFace(bitmapfont.FaceTCEA),
+		bitmapfont.NewScaledFace(bitmapfont.Face, 2),
+	)
+	strs := []string{
+		"",
+		"Hello, World!",
+		"Type «αβγ» あいう\n",
+		"a\u0301e\u0308",
+		"\u0301a",
+		"\U00020086\U0010ffff\xff",
+	}
+	for _, f := range faces {
+		for _, s := range strs {
+			want := font.MeasureString(f, s)
+			if got := bitmapfont.MeasureString(f, s); got != want {
+				t.Errorf("MeasureString(%T, %q): got: %v, want: %v", f, s, got, want)
+			}
+			if got := bitmapfont.MeasureBytes(f, []byte(s)); got != want {
+				t.Errorf("MeasureBytes(%T, %q): got: %v, want: %v", f, s, got, want)
+			}
+		}
+	}
+}
+
+func TestPreload(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face, bitmapfont.FaceTC)
+
+	l := bitmapfont.NewLazyFace("data/face_ja.bin", 12, false)
+	if err := bitmapfont.Preload(context.Background(), bitmapfont.NewScaledFace(l, 2), bitmapfont.FaceTC); err != nil {
+		t.Fatal(err)
+	}
+	if err := bitmapfont.Err(l); err != nil {
+		t.Error(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := bitmapfont.Preload(ctx, bitmapfont.NewLazyFace("data/face_ja.bin", 12, false)); err != context.Canceled {
+		t.Errorf("Preload with a canceled context: got: %v, want: %v", err, context.Canceled)
+	}
+
+	missing := bitmapfont.NewSubsetFace(os.DirFS("data"), "missing.bin")
+	if err := bitmapfont.Err(bitmapfont.NewBoldFace(missing)); err == nil {
+		t.Error("Err for a missing face must return an error")
+	}
+	if err := bitmapfont.Preload(context.Background(), bitmapfont.Face, missing); err == nil {
+		t.Error("Preload for a missing face must return an error")
+	}
+	unavailable := bitmapfont.NewEmbeddedFace("FaceXX", "data/face_xx.bin", 12, false, false, "bitmapfont_noxx")
+	if err := bitmapfont.Err(unavailable); err == nil {
+		t.Error("Err for an unavailable face must return an error")
+	}
+}
+
+func TestInvalidAtlas(t *testing.T) {
+	bin, err := os.ReadFile("data/face_ja.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := slices.Clone(bin)
+	broken[len(broken)/2] ^= 0xff
+	// The cell width follows the magic, the version, the checksum, and the size.
+	odd := slices.Clone(bin)
+	odd[11] = 11
+	binary.BigEndian.PutUint32(odd[6:10], crc32.ChecksumIEEE(odd[10:]))
+	fsys := fstest.MapFS{
+		"face.bin":    {Data: bin},
+		"broken.bin":  {Data: broken},
+		"odd.bin":     {Data: odd},
+		"short.bin":   {Data: bin[:len(bin)/2]},
+		"unknown.bin": {Data: []byte("unknown")},
+	}
+
+	if err := bitmapfont.Err(bitmapfont.NewSubsetFace(fsys, "face.bin")); err != nil {
+		t.Error(err)
+	}
+	for _, tc := range []struct {
+		name string
+		face font.Face
+		want string
+	}{
+		{"broken", bitmapfont.NewSubsetFace(fsys, "broken.bin"), "checksum mismatch"},
+		{"short", bitmapfont.NewSubsetFace(fsys, "short.bin"), "checksum mismatch"},
+		{"unknown", bitmapfont.NewSubsetFace(fsys, "unknown.bin"), "not an atlas"},
+		{"odd", bitmapfont.NewSubsetFace(fsys, "odd.bin"), "odd cell width"},
+	} {
+		err := bitmapfont.Err(tc.face)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got: %v, want: an error containing %q", tc.name, err, tc.want)
+		}
+	}
+
+	// An embedded face must match with the size and East Asian wide of the atlas.
+	skipIfUnavailable(t, bitmapfont.Face)
+	for _, tc := range []struct {
+		name string
+		face font.Face
+	}{
+		{"size", bitmapfont.NewLazyFace("data/face_ja.bin", 10, false)},
+		{"ea", bitmapfont.NewLazyFace("data/face_ja.bin", 12, true)},
+	} {
+		if err := bitmapfont.Err(tc.face); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+			t.Errorf("%s: got: %v, want: an error containing %q", tc.name, err, "doesn't match")
+		}
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	clear(b)
+	return len(b), nil
+}
+
+func TestLoadFace(t *testing.T) {
+	skipIfUnavailable(t, bitmapfont.Face, bitmapfont.FaceKOEA)
+
+	f, err := bitmapfont.LoadFaceFS(os.DirFS("data"), "face_ko_ea.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin, err := os.ReadFile("data/face_ja.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := bitmapfont.LoadFace(bytes.NewReader(bin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Hello, 世界", "※한국어"} {
+		if got, want := font.MeasureString(f, s), font.MeasureString(bitmapfont.FaceKOEA, s); got != want {
+			t.Errorf("LoadFaceFS: width for %q: got: %v, want: %v", s, got, want)
+		}
+		if got, want := font.MeasureString(g, s), font.MeasureString(bitmapfont.Face, s); got != want {
+			t.Errorf("LoadFace: width for %q: got: %v, want: %v", s, got, want)
+		}
+	}
+
+	ko, err := os.ReadFile("data/face_ko_ea.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bitmapfont.LoadFace(bytes.NewReader(ko)); err == nil {
+		t.Error("LoadFace for an atlas with a base must return an error")
+	}
+	if _, err := bitmapfont.LoadFace(io.LimitReader(zeroReader{}, 1<<30)); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("LoadFace for a too large input: got: %v, want: an error containing %q", err, "too large")
+	}
+	if _, err := bitmapfont.LoadFace(bytes.NewReader(bin[:len(bin)-1])); err == nil {
+		t.Error("LoadFace for a truncated input must return an error")
+	}
+
+	// The last bytes are in the last page. Break the page and recompute the checksum.
+	broken := slices.Clone(bin)
+	for i := len(broken) - 64; i < len(broken); i++ {
+		broken[i] = 0xff
+	}
+	binary.BigEndian.PutUint32(broken[6:10], crc32.ChecksumIEEE(broken[10:]))
+	if _, err := bitmapfont.LoadFace(bytes.NewReader(broken)); err == nil || !strings.Contains(err.Error(), "page") {
+		t.Errorf("LoadFace for a broken page: got: %v, want: an error containing %q", err, "page")
+	}
+
+	// face_ko_ea.bin refers to face_ko.bin as its base, so the renamed atlas refers to itself.
+	fsys := fstest.MapFS{
+		"face_ko.bin": &fstest.MapFile{Data: ko},
+	}
+	if _, err := bitmapfont.LoadFaceFS(fsys, "face_ko.bin"); err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Errorf("LoadFaceFS for an atlas referring to itself: got: %v, want: an error containing %q", err, "circular")
+	}
+}
+
+func TestGlyphBitmap(t *testing.T) {
+	faces := availableFaces(
+		bitmapfont.Face,
+		bitmapfont.FaceTC,
+		bitmapfont.NewProportionalFace(bitmapfont.Face),
+		bitmapfont.NewScaledFace(bitmapfont.Face10, 2),
+	)
+	for _, f := range faces {
+		for _, r := range "Ag。あ\U00020086" {
+			g, ok := bitmapfont.GlyphBitmap(f, r)
+			dr, mask, maskp, advance, ok2 := f.Glyph(fixed.P(0, 0), r)
+			if ok != ok2 {
+				t.Fatalf("GlyphBitmap(%T, %q): got: %t, want: %t", f, r, ok, ok2)
+			}
+			if !ok {
+				continue
+			}
+			if got, want := image.Rect(g.OffsetX, -g.Ascent, g.OffsetX+g.Width, g.Height-g.Ascent), dr; got != want {
+				t.Errorf("GlyphBitmap(%T, %q): bounds: got: %v, want: %v", f, r, got, want)
+			}
+			if got, want := g.Advance, advance.Round(); got != want {
+				t.Errorf("GlyphBitmap(%T, %q): advance: got: %d, want: %d", f, r, got, want)
+			}
+			a := g.Alpha()
+			for j := 0; j < dr.Dy(); j++ {
+				for i := 0; i < dr.Dx(); i++ {
+					_, _, _, want := mask.At(maskp.X+i, maskp.Y+j).RGBA()
+					if got := g.Bit(i, j); got != (want != 0) {
+						t.Fatalf("GlyphBitmap(%T, %q): bit at (%d, %d): got: %t, want: %t", f, r, i, j, got, want != 0)
+					}
+					if got := a.AlphaAt(i, j).A; got != uint8(want>>8) {
+						t.Fatalf("GlyphBitmap(%T, %q).Alpha(): alpha at (%d, %d): got: %d, want: %d", f, r, i, j, got, want>>8)
+					}
+				}
+			}
+		}
+	}
+
+	skipIfUnavailable(t, bitmapfont.Face10)
+	var n int
+	prev := rune(-1)
+	for r, g := range bitmapfont.Glyphs(bitmapfont.Face10) {
+		if r <= prev {
+			t.Fatalf("Glyphs: runes must be in ascending order: %q after %q", r, prev)
+		}
+		if !bitmapfont.HasGlyph(bitmapfont.Face10, r) || g.Height == 0 {
+			t.Fatalf("Glyphs: invalid glyph for %q", r)
+		}
+		prev = r
+		n++
+	}
+	var want int
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if bitmapfont.HasGlyph(bitmapfont.Face10, r) {
+			want++
+		}
+	}
+	if n != want {
+		t.Errorf("Glyphs: got: %d glyphs, want: %d", n, want)
+	}
+	for range bitmapfont.Glyphs(bitmapfont.Face10) {
+		break
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"golang.org/x/image/font"
+)
+
+func init() {
+	FaceKO = newEmbeddedFace("FaceKO", "data/face_ko.bin", 12, false, koAvailable, "bitmapfont_noko")
+	FaceKOEA = newEmbeddedFace("FaceKOEA", "data/face_ko_ea.bin", 12, true, koAvailable && eaAvailable, "bitmapfont_noko", "bitmapfont_noea")
+}
+
+var (
+	// FaceKO is a font.Face of the bitmap font (12px regular, prefer Korean characters).
+	FaceKO font.Face
+
+	// FaceKOEA is a font.Face of the bitmap font (12px regular, prefer Korean characters and East Asia wide characters).
+	FaceKOEA font.Face
+)
@@ -19,8 +19,8 @@ import (
 )
 
 func init() {
-	FaceSC = newDelayedFace("data/face_zhhans.bin", false)
-	FaceSCEA = newDelayedFace("data/face_zhhans_ea.bin", true)
+	FaceSC = newEmbeddedFace("FaceSC", "data/face_zhhans.bin", 12, false, scAvailable, "bitmapfont_nosc")
+	FaceSCEA = newEmbeddedFace("FaceSCEA", "data/face_zhhans_ea.bin", 12, true, scAvailable && eaAvailable, "bitmapfont_nosc", "bitmapfont_noea")
 }
 
 var (
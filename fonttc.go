@@ -19,11 +19,13 @@ import (
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
 )
 
 func init() {
-	FaceTC = &tcFace{face: newDelayedFace("data/face_zhhant.bin", false)}
-	FaceTCEA = &tcFace{face: newDelayedFace("data/face_zhhant_ea.bin", true)}
+	FaceTC = &tcFace{face: newEmbeddedFace("FaceTC", "data/face_zhhant.bin", 12, false, tcAvailable, "bitmapfont_notc")}
+	FaceTCEA = &tcFace{face: newEmbeddedFace("FaceTCEA", "data/face_zhhant_ea.bin", 12, true, tcAvailable && eaAvailable, "bitmapfont_notc", "bitmapfont_noea")}
 }
 
 var _ font.Face = (*tcFace)(nil)
@@ -36,16 +38,25 @@ func (t *tcFace) Close() error {
 	return t.face.Close()
 }
 
+// isCenteredPunctuation reports whether r is a punctuation that is moved to the center of the glyph region in traditional Chinese.
+func isCenteredPunctuation(r rune) bool {
+	return r == '、' || r == '，' || r == '。' || r == '．'
+}
+
 func (t *tcFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
 	dr, mask, maskp, advance, ok = t.face.Glyph(dot, r)
-	if r == '、' || r == '，' || r == '。' || r == '．' {
+	if isCenteredPunctuation(r) {
 		dr = dr.Add(image.Pt(3, -3))
 	}
 	return dr, mask, maskp, advance, ok
 }
 
 func (t *tcFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
-	return t.face.GlyphBounds(r)
+	bounds, advance, ok = t.face.GlyphBounds(r)
+	if ok && isCenteredPunctuation(r) && !bounds.Empty() {
+		bounds = bounds.Add(fixed.P(3, -3))
+	}
+	return bounds, advance, ok
 }
 
 func (t *tcFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
@@ -60,6 +71,14 @@ func (t *tcFace) Metrics() font.Metrics {
 	return t.face.Metrics()
 }
 
+func (t *tcFace) glyphCoverage() *bitmap.Coverage {
+	return faceCoverage(t.face)
+}
+
+func (t *tcFace) underlyingFaces() []font.Face {
+	return []font.Face{t.face}
+}
+
 var (
 	// FaceTC is a font.Face of the bitmap font (12px regular, prefer traditional Chinese characters).
 	FaceTC font.Face
@@ -17,10 +17,16 @@ package bitmapfont
 //go:generate go run -C=_gen . -widths -output ./../internal/bitmap/widths.go
 
 //go:generate go run -C=_gen . -lang ja -output ./../data/face_ja.bin
-//go:generate go run -C=_gen . -lang ja -eastasia -output ./../data/face_ja_ea.bin
-//go:generate go run -C=_gen . -lang zh-Hans -output ./../data/face_zhhans.bin
-//go:generate go run -C=_gen . -lang zh-Hans -eastasia -output ./../data/face_zhhans_ea.bin
-//go:generate go run -C=_gen . -lang zh-Hant -output ./../data/face_zhhant.bin
-//go:generate go run -C=_gen . -lang zh-Hant -eastasia -output ./../data/face_zhhant_ea.bin
+//go:generate go run -C=_gen . -lang ja -eastasia -base ./../data/face_ja.bin -output ./../data/face_ja_ea.bin
+//go:generate go run -C=_gen . -lang ko -base ./../data/face_ja.bin -output ./../data/face_ko.bin
+//go:generate go run -C=_gen . -lang ko -eastasia -base ./../data/face_ko.bin -output ./../data/face_ko_ea.bin
+//go:generate go run -C=_gen . -lang zh-Hans -base ./../data/face_ja.bin -output ./../data/face_zhhans.bin
+//go:generate go run -C=_gen . -lang zh-Hans -eastasia -base ./../data/face_zhhans.bin -output ./../data/face_zhhans_ea.bin
+//go:generate go run -C=_gen . -lang zh-Hant -base ./../data/face_ja.bin -output ./../data/face_zhhant.bin
+//go:generate go run -C=_gen . -lang zh-Hant -eastasia -base ./../data/face_zhhant.bin -output ./../data/face_zhhant_ea.bin
+//go:generate go run -C=_gen . -size 10 -lang ja -output ./../data/face10_ja.bin
+//go:generate go run -C=_gen . -size 10 -lang ja -eastasia -base ./../data/face10_ja.bin -output ./../data/face10_ja_ea.bin
+
+//go:generate go run -C=_gen . -subset ./../testdata/subset.txt -output ./../testdata/subset/face.bin
 
 //go:generate gofmt -s -w .
@@ -0,0 +1,164 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+	"iter"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Glyph represents the bitmap of a glyph.
+type Glyph struct {
+	// Bits is the bitmap of the glyph row by row from the top, and each row is Stride bytes.
+	// The leftmost pixel of a row is the most significant bit of the first byte of the row.
+	Bits   []byte
+	Stride int
+
+	// Width and Height are the size of the bitmap in pixels.
+	Width  int
+	Height int
+
+	// OffsetX is the horizontal distance from the dot to the left of the bitmap in pixels.
+	OffsetX int
+
+	// Ascent is the distance from the top of the bitmap to the baseline in pixels.
+	Ascent int
+
+	// Advance is the advance width in pixels.
+	Advance int
+}
+
+// Bit reports whether the pixel at (x, y) in the bitmap is set.
+// Bit returns false if (x, y) is out of the bitmap.
+func (g *Glyph) Bit(x, y int) bool {
+	if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return false
+	}
+	return (g.Bits[y*g.Stride+x/8]>>uint(7-x%8))&1 != 0
+}
+
+// Alpha returns the bitmap as an *image.Alpha whose bounds are (0, 0)-(Width, Height).
+// A set pixel is opaque, and the other pixels are transparent.
+func (g *Glyph) Alpha() *image.Alpha {
+	a := image.NewAlpha(image.Rect(0, 0, g.Width, g.Height))
+	for j := 0; j < g.Height; j++ {
+		for i := 0; i < g.Width; i++ {
+			if g.Bit(i, j) {
+				a.Pix[j*a.Stride+i] = 0xff
+			}
+		}
+	}
+	return a
+}
+
+// newGlyph creates a Glyph from the results of font.Face's Glyph or bitmap.Face's GlyphBits with the dot at the origin.
+func newGlyph(dr image.Rectangle, bits []byte, stride int, advance fixed.Int26_6) Glyph {
+	return Glyph{
+		Bits:    bits,
+		Stride:  stride,
+		Width:   dr.Dx(),
+		Height:  dr.Dy(),
+		OffsetX: dr.Min.X,
+		Ascent:  -dr.Min.Y,
+		Advance: advance.Round(),
+	}
+}
+
+type glyphBitmapFace interface {
+	glyphBitmap(r rune) (Glyph, bool)
+}
+
+// GlyphBitmap returns the bitmap of the glyph for r in face.
+// GlyphBitmap returns false if face doesn't have a glyph for r.
+//
+// For Face and the other faces of this package, GlyphBitmap reads the bits directly from the atlas.
+// For the other font.Face values, a pixel is set when the alpha value of the glyph mask is at least 50%.
+func GlyphBitmap(face font.Face, r rune) (Glyph, bool) {
+	if f, ok := face.(glyphBitmapFace); ok {
+		return f.glyphBitmap(r)
+	}
+
+	dr, mask, maskp, advance, ok := face.Glyph(fixed.Point26_6{}, r)
+	if !ok {
+		return Glyph{}, false
+	}
+	stride := (dr.Dx() + 7) / 8
+	bits := make([]byte, stride*dr.Dy())
+	for j := 0; j < dr.Dy(); j++ {
+		for i := 0; i < dr.Dx(); i++ {
+			if _, _, _, a := mask.At(maskp.X+i, maskp.Y+j).RGBA(); a >= 0x8000 {
+				bits[j*stride+i/8] |= 1 << uint(7-i%8)
+			}
+		}
+	}
+	return newGlyph(dr, bits, stride, advance), true
+}
+
+// Glyphs returns an iterator over all the glyphs in face in ascending order of the runes.
+//
+// face must be a font.Face of this package like Face.
+// Glyphs yields nothing if face is not a font.Face of this package.
+// Glyphs panics if the data of face is excluded by the build tags in the same way as drawing with face.
+func Glyphs(face font.Face) iter.Seq2[rune, Glyph] {
+	return func(yield func(rune, Glyph) bool) {
+		c := faceCoverage(face)
+		if c == nil {
+			return
+		}
+		t := c.RangeTable()
+		for _, rng := range t.R16 {
+			for r := rune(rng.Lo); r <= rune(rng.Hi); r += rune(rng.Stride) {
+				if g, ok := GlyphBitmap(face, r); ok && !yield(r, g) {
+					return
+				}
+			}
+		}
+		for _, rng := range t.R32 {
+			for r := rune(rng.Lo); r <= rune(rng.Hi); r += rune(rng.Stride) {
+				if g, ok := GlyphBitmap(face, r); ok && !yield(r, g) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (f *lazyFace) glyphBitmap(r rune) (Glyph, bool) {
+	dr, bits, stride, advance, ok := f.bitmapFaces().regular.GlyphBits(r)
+	if !ok {
+		return Glyph{}, false
+	}
+	return newGlyph(dr, bits, stride, advance), true
+}
+
+func (p *proportionalFace) glyphBitmap(r rune) (Glyph, bool) {
+	dr, bits, stride, advance, ok := p.face.bitmapFaces().proportional.GlyphBits(r)
+	if !ok {
+		return Glyph{}, false
+	}
+	return newGlyph(dr, bits, stride, advance), true
+}
+
+func (t *tcFace) glyphBitmap(r rune) (Glyph, bool) {
+	g, ok := GlyphBitmap(t.face, r)
+	if ok && isCenteredPunctuation(r) {
+		g.OffsetX += 3
+		g.Ascent += 3
+	}
+	return g, ok
+}
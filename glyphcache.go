@@ -0,0 +1,79 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// cachedGlyph is a glyph generated from another face's glyph.
+type cachedGlyph struct {
+	// dr is the destination rectangle when the dot is (0, 0).
+	dr      image.Rectangle
+	mask    *image.Alpha
+	advance fixed.Int26_6
+	ok      bool
+}
+
+// glyph returns the values for font.Face's Glyph.
+func (c *cachedGlyph) glyph(dot fixed.Point26_6) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	if !c.ok {
+		return
+	}
+	return c.dr.Add(image.Pt(dot.X.Floor(), dot.Y.Floor())), c.mask, c.mask.Rect.Min, c.advance, true
+}
+
+// glyphCache caches glyphs generated from another face's glyphs.
+//
+// A face with a glyphCache doesn't allocate memory in Glyph for a rune that has already been rendered.
+type glyphCache struct {
+	face font.Face
+
+	// generate generates a glyph from the face's glyph at the dot (0, 0).
+	// srcMask is the region of the source glyph in the source mask.
+	generate func(dr image.Rectangle, mask image.Image, srcMask image.Rectangle, advance fixed.Int26_6) cachedGlyph
+
+	glyphs map[rune]*cachedGlyph
+	m      sync.Mutex
+}
+
+func (c *glyphCache) get(r rune) *cachedGlyph {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if g, ok := c.glyphs[r]; ok {
+		return g
+	}
+
+	var g cachedGlyph
+	if dr, mask, maskp, advance, ok := c.face.Glyph(fixed.Point26_6{}, r); ok {
+		g = c.generate(dr, mask, image.Rectangle{Min: maskp, Max: maskp.Add(dr.Size())}, advance)
+	}
+	if c.glyphs == nil {
+		c.glyphs = map[rune]*cachedGlyph{}
+	}
+	c.glyphs[r] = &g
+	return &g
+}
+
+func (c *glyphCache) clear() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.glyphs = nil
+}
@@ -15,20 +15,20 @@
 package bitmap
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
-	"unicode"
+	"sync"
+	"unicode/utf8"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
-	"golang.org/x/text/width"
-
-	iunicode "github.com/hajimehoshi/bitmapfont/v4/internal/unicode"
 )
 
 type BinaryImage struct {
 	bits   []byte
+	pages  *pages
 	width  int
 	height int
 	bounds image.Rectangle
@@ -43,15 +43,107 @@ func NewBinaryImage(bits []byte, width, height int) *BinaryImage {
 	}
 }
 
+// pages represents the bits of an image divided into horizontal bands.
+// The bits of a page are loaded on the first access.
+type pages struct {
+	pageHeight int
+	load       func(page int) ([]byte, error)
+	bits       []pageBits
+}
+
+type pageBits struct {
+	once sync.Once
+	bits []byte
+	err  error
+}
+
+func (p *pages) at(page int) ([]byte, error) {
+	b := &p.bits[page]
+	b.once.Do(func() {
+		b.bits, b.err = p.load(page)
+	})
+	return b.bits, b.err
+}
+
+// NewPagedBinaryImage creates a new BinaryImage whose bits are divided into pages.
+//
+// A page is a horizontal band of the image, and its height is pageHeight.
+// load is called to get the bits of a page when any pixel of the page is accessed for the first time.
+// load can return nil for an empty page.
+// load can be called concurrently for different pages.
+// If load returns an error, accessing the pixels of the page panics.
+//
+// SetBit must not be called for the image.
+func NewPagedBinaryImage(width, height, pageHeight int, load func(page int) ([]byte, error)) *BinaryImage {
+	return &BinaryImage{
+		pages: &pages{
+			pageHeight: pageHeight,
+			load:       load,
+			bits:       make([]pageBits, (height+pageHeight-1)/pageHeight),
+		},
+		width:  width,
+		height: height,
+		bounds: image.Rect(0, 0, width, height),
+	}
+}
+
+// LoadPages loads all the pages of a paged image in advance.
+// LoadPages returns the first error of loading a page, or ctx.Err() if ctx is done before all the pages are loaded.
+// LoadPages does nothing for an image that is not paged.
+func (b *BinaryImage) LoadPages(ctx context.Context) error {
+	if b.pages == nil {
+		return nil
+	}
+	for i := range b.pages.bits {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := b.pages.at(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *BinaryImage) At(i, j int) color.Color {
+	if b.Bit(i, j) {
+		return color.Alpha{0xff}
+	}
+	return color.Alpha{0}
+}
+
+// Bit reports whether the pixel at (i, j) is set.
+func (b *BinaryImage) Bit(i, j int) bool {
 	if i < b.bounds.Min.X || j < b.bounds.Min.Y || i >= b.bounds.Max.X || j >= b.bounds.Max.Y {
-		return color.Alpha{0}
+		return false
+	}
+	bits := b.bits
+	if b.pages != nil {
+		var err error
+		bits, err = b.pages.at(j / b.pages.pageHeight)
+		if err != nil {
+			panic(err)
+		}
+		if bits == nil {
+			return false
+		}
+		j %= b.pages.pageHeight
 	}
 	idx := b.width*j + i
-	if (b.bits[idx/8]>>uint(7-idx%8))&1 != 0 {
-		return color.Alpha{0xff}
+	return (bits[idx/8]>>uint(7-idx%8))&1 != 0
+}
+
+// SetBit sets the pixel at (i, j).
+// SetBit does nothing if (i, j) is out of the bounds.
+func (b *BinaryImage) SetBit(i, j int) {
+	if b.pages != nil {
+		panic("bitmap: SetBit cannot be called for a paged image")
 	}
-	return color.Alpha{0}
+	if i < b.bounds.Min.X || j < b.bounds.Min.Y || i >= b.bounds.Max.X || j >= b.bounds.Max.Y {
+		return
+	}
+	idx := b.width*j + i
+	b.bits[idx/8] |= 1 << uint(7-idx%8)
 }
 
 func (b *BinaryImage) ColorModel() color.Model {
@@ -69,6 +161,7 @@ func (b *BinaryImage) SubImage(r image.Rectangle) image.Image {
 	}
 	return &BinaryImage{
 		bits:   b.bits,
+		pages:  b.pages,
 		width:  b.width,
 		height: b.height,
 		bounds: bounds,
@@ -82,53 +175,217 @@ const (
 
 type Face struct {
 	image        *BinaryImage
+	coverage     *Coverage
+	fullWidth    int
+	height       int
 	dotX         fixed.Int26_6
 	dotY         fixed.Int26_6
 	eastAsiaWide bool
+	proportional bool
+
+	capHeight  int
+	xHeight    int
+	caretSlope image.Point
+
+	proportionalGlyphs map[rune]ProportionalGlyph
+	proportionalKerns  map[[2]rune]int
+
+	// alphas is shared with the faces returned by Proportional.
+	alphas *alphaCache
+}
+
+// alphaCache is a cache of the glyph regions as *image.Alpha.
+// image/draw has fast paths for *image.Alpha masks.
+type alphaCache struct {
+	alphas map[rune]*image.Alpha
+	m      sync.RWMutex
+}
+
+func newAlphaCache() *alphaCache {
+	return &alphaCache{
+		alphas: map[rune]*image.Alpha{},
+	}
+}
+
+// ProportionalGlyph represents the horizontal ink position of a glyph in its halfwidth glyph region.
+type ProportionalGlyph struct {
+	// Left is the leftmost ink column.
+	Left int
+
+	// Advance is the proportional advance including an empty column on the right side.
+	Advance int
 }
 
-func NewFace(image *BinaryImage, dotX, dotY fixed.Int26_6, eastAsiaWide bool) *Face {
+// FaceOptions represents the layout of the glyphs in an atlas image.
+type FaceOptions struct {
+	// CellWidth and CellHeight are the size of a fullwidth glyph region.
+	// A halfwidth glyph region is the left half of it.
+	CellWidth  int
+	CellHeight int
+
+	// DotX and DotY are the dot position in a glyph region.
+	DotX fixed.Int26_6
+	DotY fixed.Int26_6
+
+	EastAsiaWide bool
+
+	// CapHeight and XHeight are the heights of the ink of 'H' and 'x' above the baseline in pixels.
+	CapHeight int
+	XHeight   int
+
+	// CaretSlope is the slope of the caret as font.Metrics's CaretSlope.
+	CaretSlope image.Point
+
+	// ProportionalGlyphs is the glyphs that have proportional advances in the face returned by Proportional.
+	ProportionalGlyphs map[rune]ProportionalGlyph
+
+	// ProportionalKerns is the kerning values for the pairs of the proportional glyphs.
+	ProportionalKerns map[[2]rune]int
+}
+
+// NewFace creates a new Face.
+//
+// The rows of glyphs in image are laid out as coverage describes.
+// NewFace returns an error if the size of image doesn't match with coverage and options.
+func NewFace(image *BinaryImage, coverage *Coverage, options *FaceOptions) (*Face, error) {
+	if options.CellWidth <= 0 || options.CellHeight <= 0 || options.CellWidth%2 != 0 {
+		return nil, fmt.Errorf("bitmap: invalid cell size: %dx%d", options.CellWidth, options.CellHeight)
+	}
+	if w, h := image.Bounds().Dx(), image.Bounds().Dy(); w != options.CellWidth*charXNum || h != options.CellHeight*coverage.rowCount() {
+		return nil, fmt.Errorf("bitmap: image size mismatch: got: %dx%d, want: %dx%d", w, h, options.CellWidth*charXNum, options.CellHeight*coverage.rowCount())
+	}
 	return &Face{
 		image:        image,
-		dotX:         dotX,
-		dotY:         dotY,
-		eastAsiaWide: eastAsiaWide,
+		coverage:     coverage,
+		fullWidth:    options.CellWidth,
+		height:       options.CellHeight,
+		dotX:         options.DotX,
+		dotY:         options.DotY,
+		eastAsiaWide: options.EastAsiaWide,
+
+		capHeight:  options.CapHeight,
+		xHeight:    options.XHeight,
+		caretSlope: options.CaretSlope,
+
+		proportionalGlyphs: options.ProportionalGlyphs,
+		proportionalKerns:  options.ProportionalKerns,
+
+		alphas: newAlphaCache(),
+	}, nil
+}
+
+// glyphPosition returns the upper-left position of the glyph region for r in the image.
+// glyphPosition returns false when the face doesn't have a glyph for r.
+func (f *Face) glyphPosition(r rune) (image.Point, bool) {
+	if !f.coverage.Has(r) {
+		return image.Point{}, false
 	}
+	row, _ := f.coverage.row(r)
+	return image.Pt((int(r)%charXNum)*f.charFullWidth(), row*f.charHeight()), true
+}
+
+// Proportional returns a face sharing the image with f,
+// where Latin, Greek, and Cyrillic halfwidth glyphs have their own advances based on their ink.
+func (f *Face) Proportional() *Face {
+	pf := *f
+	pf.proportional = true
+	return &pf
+}
+
+// proportionalGlyph returns the proportional glyph information for r.
+// proportionalGlyph returns false when f is not proportional or r is not a proportional halfwidth glyph.
+func (f *Face) proportionalGlyph(r rune) (ProportionalGlyph, bool) {
+	return f.proportionalGlyphForClass(r, lookupRuneClass(r))
+}
+
+func (f *Face) proportionalGlyphForClass(r rune, class runeClass) (ProportionalGlyph, bool) {
+	if !f.proportional {
+		return ProportionalGlyph{}, false
+	}
+	// East Asian ambiguous glyphs can be fullwidth glyphs from another font.
+	if f.cellWidthForClass(class) != f.charHalfWidth() {
+		return ProportionalGlyph{}, false
+	}
+	g, ok := f.proportionalGlyphs[r]
+	return g, ok
 }
 
 func (f *Face) runeWidth(r rune) int {
-	// For Latin glyphs, M+ doesn't work. Use the fixed font whatever the face is.
-	if iunicode.IsLatin(r) {
-		return f.charHalfWidth()
+	if g, ok := f.proportionalGlyph(r); ok {
+		return g.Advance
 	}
+	return f.cellWidth(r)
+}
 
-	if _, ok := wideRunes[r]; ok {
-		return f.charFullWidth()
+// runeMetrics is the information of a rune to measure a string.
+type runeMetrics struct {
+	advance      int
+	nonspacing   bool
+	proportional bool
+}
+
+// runeMetrics returns the information of r to measure a string.
+// The class and the coverage are looked up only once.
+// runeMetrics returns false when the face doesn't have a glyph for r.
+func (f *Face) runeMetrics(r rune) (runeMetrics, bool) {
+	if !f.coverage.Has(r) {
+		return runeMetrics{}, false
+	}
+	class := lookupRuneClass(r)
+	m := runeMetrics{
+		nonspacing: class&runeClassNonspacing != 0,
 	}
+	if g, ok := f.proportionalGlyphForClass(r, class); ok {
+		m.advance = g.Advance
+		m.proportional = true
+	} else {
+		m.advance = f.cellWidthForClass(class)
+	}
+	return m, true
+}
 
-	switch k := width.LookupRune(r).Kind(); k {
-	case width.Neutral:
-		return f.charHalfWidth()
-	case width.EastAsianAmbiguous:
+// kern returns the kerning value between r0 and r1 with their information.
+// r1 must have a glyph. m0 is zero when r0 doesn't have a glyph.
+func (f *Face) kern(r0 rune, m0 runeMetrics, r1 rune, m1 runeMetrics) fixed.Int26_6 {
+	if m1.nonspacing {
+		return -fixed.I(m1.advance)
+	}
+	if !m0.proportional || !m1.proportional {
+		return 0
+	}
+	return fixed.I(f.proportionalKerns[[2]rune{r0, r1}])
+}
+
+// glyphColumns returns the columns of the glyph for r relative to its glyph region.
+func (f *Face) glyphColumns(r rune) (left, width int) {
+	if g, ok := f.proportionalGlyph(r); ok {
+		// The advance includes an empty column, which might be outside of the glyph region.
+		return g.Left, min(g.Advance, f.charHalfWidth()-g.Left)
+	}
+	return 0, f.cellWidth(r)
+}
+
+// cellWidth returns the width of the glyph region for r.
+func (f *Face) cellWidth(r rune) int {
+	return f.cellWidthForClass(lookupRuneClass(r))
+}
+
+func (f *Face) cellWidthForClass(class runeClass) int {
+	switch class & runeClassWidthMask {
+	case runeClassFullwidth:
+		return f.charFullWidth()
+	case runeClassAmbiguous:
 		if f.eastAsiaWide {
 			return f.charFullWidth()
 		}
 		return f.charHalfWidth()
-	case width.EastAsianWide:
-		return f.charFullWidth()
-	case width.EastAsianNarrow:
-		return f.charHalfWidth()
-	case width.EastAsianFullwidth:
-		return f.charFullWidth()
-	case width.EastAsianHalfwidth:
-		return f.charHalfWidth()
 	default:
-		panic(fmt.Sprintf("bitmap: unexpected kind: %d", k))
+		return f.charHalfWidth()
 	}
 }
 
 func (f *Face) charFullWidth() int {
-	return f.image.Bounds().Dx() / charXNum
+	return f.fullWidth
 }
 
 func (f *Face) charHalfWidth() int {
@@ -136,7 +393,7 @@ func (f *Face) charHalfWidth() int {
 }
 
 func (f *Face) charHeight() int {
-	return f.image.Bounds().Dy() / charYNum
+	return f.height
 }
 
 func (f *Face) Close() error {
@@ -144,55 +401,187 @@ func (f *Face) Close() error {
 }
 
 func (f *Face) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
-	if r >= 0x10000 {
+	p, ok := f.glyphPosition(r)
+	if !ok {
 		return
 	}
 
-	rw := f.runeWidth(r)
+	left, w := f.glyphColumns(r)
 	dx := (dot.X - f.dotX).Floor()
 	dy := (dot.Y - f.dotY).Floor()
-	dr = image.Rect(dx, dy, dx+rw, dy+f.charHeight())
+	dr = image.Rect(dx, dy, dx+w, dy+f.charHeight())
+
+	maskp = image.Pt(p.X+left, p.Y)
+	mask = f.alpha(r, p)
+	advance = fixed.I(f.runeWidth(r))
+	return
+}
+
+// GlyphBits returns the bits of the glyph for r in the same way as Glyph with the dot at the origin.
+//
+// bits has the rows of dr from the top, and each row is stride bytes.
+// The leftmost pixel of a row is the most significant bit of the first byte of the row.
+func (f *Face) GlyphBits(r rune) (dr image.Rectangle, bits []byte, stride int, advance fixed.Int26_6, ok bool) {
+	p, ok := f.glyphPosition(r)
+	if !ok {
+		return
+	}
 
-	mx := (int(r) % charXNum) * f.charFullWidth()
-	my := (int(r) / charXNum) * f.charHeight()
-	mask = f.image.SubImage(image.Rect(mx, my, mx+rw, my+f.charHeight()))
-	maskp = image.Pt(mx, my)
+	left, w := f.glyphColumns(r)
+	dx := (-f.dotX).Floor()
+	dy := (-f.dotY).Floor()
+	dr = image.Rect(dx, dy, dx+w, dy+f.charHeight())
+
+	stride = (w + 7) / 8
+	bits = make([]byte, stride*dr.Dy())
+	for j := 0; j < dr.Dy(); j++ {
+		for i := 0; i < w; i++ {
+			if f.image.Bit(p.X+left+i, p.Y+j) {
+				bits[j*stride+i/8] |= 1 << uint(7-i%8)
+			}
+		}
+	}
 	advance = fixed.I(f.runeWidth(r))
-	ok = true
 	return
 }
 
+// alpha returns the glyph region for r at p as an *image.Alpha.
+// The bounds of the returned image are the same as the glyph region in f.image.
+func (f *Face) alpha(r rune, p image.Point) *image.Alpha {
+	f.alphas.m.RLock()
+	a, ok := f.alphas.alphas[r]
+	f.alphas.m.RUnlock()
+	if ok {
+		return a
+	}
+
+	a = image.NewAlpha(image.Rect(p.X, p.Y, p.X+f.cellWidth(r), p.Y+f.charHeight()))
+	for j := a.Rect.Min.Y; j < a.Rect.Max.Y; j++ {
+		for i := a.Rect.Min.X; i < a.Rect.Max.X; i++ {
+			if f.image.Bit(i, j) {
+				a.SetAlpha(i, j, color.Alpha{0xff})
+			}
+		}
+	}
+
+	f.alphas.m.Lock()
+	defer f.alphas.m.Unlock()
+	// Another goroutine might have added the same glyph.
+	if a, ok := f.alphas.alphas[r]; ok {
+		return a
+	}
+	f.alphas.alphas[r] = a
+	return a
+}
+
+// GlyphBounds returns the bounds of the ink of the glyph for r.
+// The bounds are empty when the glyph has no ink, e.g., a space.
 func (f *Face) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
-	if r >= 0x10000 {
+	p, ok := f.glyphPosition(r)
+	if !ok {
+		return
+	}
+	advance = fixed.I(f.runeWidth(r))
+
+	left, w := f.glyphColumns(r)
+	ink := image.Rectangle{}
+	for j := 0; j < f.charHeight(); j++ {
+		for i := 0; i < w; i++ {
+			if !f.image.Bit(p.X+left+i, p.Y+j) {
+				continue
+			}
+			ink = ink.Union(image.Rect(i, j, i+1, j+1))
+		}
+	}
+	if ink.Empty() {
 		return
 	}
 	bounds = fixed.Rectangle26_6{
-		Min: fixed.Point26_6{X: -f.dotX, Y: -f.dotY},
-		Max: fixed.Point26_6{X: -f.dotX + fixed.I(f.runeWidth(r)), Y: -f.dotY + fixed.I(f.charHeight())},
+		Min: fixed.Point26_6{X: fixed.I(ink.Min.X) - f.dotX, Y: fixed.I(ink.Min.Y) - f.dotY},
+		Max: fixed.Point26_6{X: fixed.I(ink.Max.X) - f.dotX, Y: fixed.I(ink.Max.Y) - f.dotY},
 	}
-	advance = fixed.I(f.runeWidth(r))
-	ok = true
 	return
 }
 
 func (f *Face) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
-	if r >= 0x10000 {
+	m, ok := f.runeMetrics(r)
+	if !ok {
 		return 0, false
 	}
-	return fixed.I(f.runeWidth(r)), true
+	return fixed.I(m.advance), true
+}
+
+// IsNonspacing reports whether r is a nonspacing mark that Kern moves back to overlap the previous glyph.
+func (f *Face) IsNonspacing(r rune) bool {
+	// A missing glyph is not rendered, so there is nothing to overlap.
+	m, ok := f.runeMetrics(r)
+	return ok && m.nonspacing
 }
 
 func (f *Face) Kern(r0, r1 rune) fixed.Int26_6 {
-	if unicode.Is(unicode.Mn, r1) {
-		return -fixed.I(f.runeWidth(r1))
+	// Only a nonspacing mark and a pair of proportional glyphs have kerning values.
+	// Skip the coverage for the other runes as Kern is called for each rune.
+	if !f.proportional && lookupRuneClass(r1)&runeClassNonspacing == 0 {
+		return 0
+	}
+	m1, ok1 := f.runeMetrics(r1)
+	if !ok1 {
+		return 0
+	}
+	m0, _ := f.runeMetrics(r0)
+	return f.kern(r0, m0, r1, m1)
+}
+
+// measurer accumulates the advance of runes in the same way as font.MeasureString.
+type measurer struct {
+	face    *Face
+	advance fixed.Int26_6
+
+	// prev is the previous rune that has a glyph, or -1.
+	prev        rune
+	prevMetrics runeMetrics
+}
+
+func (m *measurer) add(r rune) {
+	rm, ok := m.face.runeMetrics(r)
+	if !ok {
+		return
+	}
+	if m.prev >= 0 {
+		m.advance += m.face.kern(m.prev, m.prevMetrics, r, rm)
+	}
+	m.advance += fixed.I(rm.advance)
+	m.prev = r
+	m.prevMetrics = rm
+}
+
+// MeasureString returns the advance of s in the same way as font.MeasureString.
+func (f *Face) MeasureString(s string) fixed.Int26_6 {
+	m := measurer{face: f, prev: -1}
+	for _, r := range s {
+		m.add(r)
+	}
+	return m.advance
+}
+
+// MeasureBytes returns the advance of b in the same way as font.MeasureBytes.
+func (f *Face) MeasureBytes(b []byte) fixed.Int26_6 {
+	m := measurer{face: f, prev: -1}
+	for len(b) > 0 {
+		r, n := utf8.DecodeRune(b)
+		b = b[n:]
+		m.add(r)
 	}
-	return 0
+	return m.advance
 }
 
 func (f *Face) Metrics() font.Metrics {
 	return font.Metrics{
-		Height:  fixed.I(f.charHeight()),
-		Ascent:  f.dotY,
-		Descent: fixed.I(f.charHeight()) - f.dotY,
+		Height:     fixed.I(f.charHeight()),
+		Ascent:     f.dotY,
+		Descent:    fixed.I(f.charHeight()) - f.dotY,
+		XHeight:    fixed.I(f.xHeight),
+		CapHeight:  fixed.I(f.capHeight),
+		CaretSlope: f.caretSlope,
 	}
 }
@@ -0,0 +1,37 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+import (
+	"image"
+)
+
+// Embolden returns a new image that is the region r of src overstruck with itself shifted by 1px to the right.
+// The returned image is 1px wider than r so that the rightmost pixels are not clipped, and its bounds starts at (0, 0).
+//
+// Only the pixels in r are read, so the glyphs next to the region in an atlas image never bleed into the result.
+func Embolden(src image.Image, r image.Rectangle) *image.Alpha {
+	s := Scale(src, r, 1)
+	dst := image.NewAlpha(image.Rect(0, 0, r.Dx()+1, r.Dy()))
+	for j := 0; j < r.Dy(); j++ {
+		srcLine := s.Pix[j*s.Stride : j*s.Stride+r.Dx()]
+		dstLine := dst.Pix[j*dst.Stride : j*dst.Stride+r.Dx()+1]
+		copy(dstLine, srcLine)
+		for i, a := range srcLine {
+			dstLine[i+1] = max(dstLine[i+1], a)
+		}
+	}
+	return dst
+}
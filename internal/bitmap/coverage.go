@@ -0,0 +1,132 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+import (
+	"slices"
+	"sync"
+	"unicode"
+)
+
+// Coverage represents the set of runes that have glyphs, and the layout of the rows in an atlas image.
+//
+// An atlas has 256 rows for the BMP, and one row for each supplementary page.
+// A page is a range of 256 runes, and the page number of a rune r is r / 256.
+type Coverage struct {
+	supplementaryPages []int
+	bits               []byte
+
+	rangeTableOnce sync.Once
+	rangeTable     *unicode.RangeTable
+}
+
+// NewCoverage creates a new Coverage.
+//
+// supplementaryPages must be sorted in ascending order.
+// bits has 256 bits for each row, and a bit is set when the rune has a glyph.
+func NewCoverage(supplementaryPages []int, bits []byte) *Coverage {
+	return &Coverage{
+		supplementaryPages: supplementaryPages,
+		bits:               bits,
+	}
+}
+
+// rowCount returns the number of the rows.
+func (c *Coverage) rowCount() int {
+	return charYNum + len(c.supplementaryPages)
+}
+
+// row returns the row index for r.
+// row returns false when there is no row for r.
+func (c *Coverage) row(r rune) (int, bool) {
+	if r < 0 || r > unicode.MaxRune {
+		return 0, false
+	}
+	row := int(r) / charXNum
+	if row >= charYNum {
+		i, ok := slices.BinarySearch(c.supplementaryPages, row)
+		if !ok {
+			return 0, false
+		}
+		row = charYNum + i
+	}
+	return row, true
+}
+
+// Has reports whether r has a glyph.
+func (c *Coverage) Has(r rune) bool {
+	row, ok := c.row(r)
+	if !ok {
+		return false
+	}
+	idx := row*charXNum + int(r)%charXNum
+	return (c.bits[idx/8]>>uint(7-idx%8))&1 != 0
+}
+
+// RangeTable returns a table of the runes that have glyphs.
+// The returned table must not be modified.
+func (c *Coverage) RangeTable() *unicode.RangeTable {
+	c.rangeTableOnce.Do(func() {
+		c.rangeTable = c.makeRangeTable()
+	})
+	return c.rangeTable
+}
+
+func (c *Coverage) makeRangeTable() *unicode.RangeTable {
+	pages := make([]int, 0, c.rowCount())
+	for page := 0; page < charYNum; page++ {
+		pages = append(pages, page)
+	}
+	pages = append(pages, c.supplementaryPages...)
+
+	t := &unicode.RangeTable{}
+	start := rune(-1)
+	addRange := func(lo, hi rune) {
+		if hi <= 0xffff {
+			t.R16 = append(t.R16, unicode.Range16{Lo: uint16(lo), Hi: uint16(hi), Stride: 1})
+			if hi <= unicode.MaxLatin1 {
+				t.LatinOffset++
+			}
+			return
+		}
+		if lo <= 0xffff {
+			t.R16 = append(t.R16, unicode.Range16{Lo: uint16(lo), Hi: 0xffff, Stride: 1})
+			lo = 0x10000
+		}
+		t.R32 = append(t.R32, unicode.Range32{Lo: uint32(lo), Hi: uint32(hi), Stride: 1})
+	}
+	last := rune(-1)
+	for _, page := range pages {
+		for i := 0; i < charXNum; i++ {
+			r := rune(page*charXNum + i)
+			if !c.Has(r) {
+				continue
+			}
+			if start >= 0 && r == last+1 {
+				last = r
+				continue
+			}
+			if start >= 0 {
+				addRange(start, last)
+			}
+			start = r
+			last = r
+		}
+	}
+	if start >= 0 {
+		addRange(start, last)
+	}
+	return t
+}
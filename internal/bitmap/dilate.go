@@ -0,0 +1,51 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+// Dilate returns a new image where each set pixel of b is expanded by n pixels in the 8 directions.
+//
+// The returned image is 2n pixels larger than b in each dimension,
+// and the pixel (i, j) of b corresponds to the pixel (i+n, j+n) of the returned image.
+// b's bounds must start at (0, 0).
+func Dilate(b *BinaryImage, n int) *BinaryImage {
+	w, h := b.bounds.Dx(), b.bounds.Dy()
+	dw, dh := w+2*n, h+2*n
+
+	// Dilate horizontally, and then vertically.
+	horizontal := NewBinaryImage(make([]byte, (dw*h+7)/8), dw, h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if !b.Bit(i, j) {
+				continue
+			}
+			for k := 0; k <= 2*n; k++ {
+				horizontal.SetBit(i+k, j)
+			}
+		}
+	}
+
+	dst := NewBinaryImage(make([]byte, (dw*dh+7)/8), dw, dh)
+	for j := 0; j < h; j++ {
+		for i := 0; i < dw; i++ {
+			if !horizontal.Bit(i, j) {
+				continue
+			}
+			for k := 0; k <= 2*n; k++ {
+				dst.SetBit(i, j+k)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+import (
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// runeClass represents the width class of a rune and whether the rune is a nonspacing mark.
+type runeClass byte
+
+const (
+	runeClassHalfwidth runeClass = iota
+	runeClassFullwidth
+
+	// runeClassAmbiguous is for a rune whose width depends on whether the face is for East Asian contexts.
+	runeClassAmbiguous
+
+	runeClassWidthMask runeClass = 0x3
+
+	// runeClassNonspacing is a flag for a nonspacing mark (Mn).
+	runeClassNonspacing runeClass = 0x4
+)
+
+func lookupRuneClass(r rune) runeClass {
+	if 0 <= r && r < 0x10000 {
+		return runeClass(bmpRuneClasses[r/2]>>(4*(r%2))) & 0xf
+	}
+
+	// The special cases for the BMP, such as Latin glyphs, don't matter here.
+	var c runeClass
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianAmbiguous:
+		c = runeClassAmbiguous
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		c = runeClassFullwidth
+	default:
+		c = runeClassHalfwidth
+	}
+	if unicode.Is(unicode.Mn, r) {
+		c |= runeClassNonspacing
+	}
+	return c
+}
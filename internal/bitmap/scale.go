@@ -0,0 +1,57 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+import (
+	"image"
+	"image/color"
+)
+
+// Scale returns a new image that is the region r of src scaled by the integer factor scale with the nearest-neighbor filter.
+// The bounds of the returned image starts at (0, 0).
+func Scale(src image.Image, r image.Rectangle, scale int) *image.Alpha {
+	dst := image.NewAlpha(image.Rect(0, 0, r.Dx()*scale, r.Dy()*scale))
+	if dst.Rect.Empty() {
+		return dst
+	}
+
+	for j := 0; j < r.Dy(); j++ {
+		// Fill the first line for the source line, and then copy it to the other lines.
+		line := dst.Pix[j*scale*dst.Stride : j*scale*dst.Stride+dst.Rect.Dx()]
+		for i := 0; i < r.Dx(); i++ {
+			var a byte
+			switch src := src.(type) {
+			case *BinaryImage:
+				if src.Bit(r.Min.X+i, r.Min.Y+j) {
+					a = 0xff
+				}
+			case *image.Alpha:
+				a = src.AlphaAt(r.Min.X+i, r.Min.Y+j).A
+			default:
+				a = color.AlphaModel.Convert(src.At(r.Min.X+i, r.Min.Y+j)).(color.Alpha).A
+			}
+			if a == 0 {
+				continue
+			}
+			for k := range scale {
+				line[i*scale+k] = a
+			}
+		}
+		for k := 1; k < scale; k++ {
+			copy(dst.Pix[(j*scale+k)*dst.Stride:], line)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmap
+
+import (
+	"image"
+	"slices"
+)
+
+// ShiftRows returns a new image that is the region r of src whose rows are shifted horizontally.
+// The j-th row of r is shifted by shifts[j] pixels to the right.
+// len(shifts) must be r.Dy().
+//
+// The bounds of the returned image is (min(shifts), 0)-(r.Dx()+max(shifts), r.Dy()),
+// so that the origin of the returned image corresponds to r.Min.
+func ShiftRows(src image.Image, r image.Rectangle, shifts []int) *image.Alpha {
+	if len(shifts) != r.Dy() {
+		panic("bitmap: len(shifts) must match with the height of the region")
+	}
+	if len(shifts) == 0 {
+		return image.NewAlpha(image.Rectangle{})
+	}
+
+	s := Scale(src, r, 1)
+	minShift, maxShift := slices.Min(shifts), slices.Max(shifts)
+	dst := image.NewAlpha(image.Rect(minShift, 0, r.Dx()+maxShift, r.Dy()))
+	for j, shift := range shifts {
+		srcLine := s.Pix[j*s.Stride : j*s.Stride+r.Dx()]
+		copy(dst.Pix[j*dst.Stride+shift-minShift:], srcLine)
+	}
+	return dst
+}
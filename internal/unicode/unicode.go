@@ -193,5 +193,19 @@ func IsCJKUnifiedIdeograph(r rune) bool {
 	if 0x3400 <= r && r <= 0x4DBF {
 		return true
 	}
+	// CJK Unified Ideographs Extension B
+	// CJK Unified Ideographs Extension C
+	// CJK Unified Ideographs Extension D
+	// CJK Unified Ideographs Extension E
+	// CJK Unified Ideographs Extension F
+	// CJK Unified Ideographs Extension I
+	if 0x20000 <= r && r <= 0x2EE5F {
+		return true
+	}
+	// CJK Unified Ideographs Extension G
+	// CJK Unified Ideographs Extension H
+	if 0x30000 <= r && r <= 0x323AF {
+		return true
+	}
 	return false
 }
@@ -0,0 +1,135 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+// LayoutOptions represents the options for WithLayout.
+// All the values are in pixels.
+type LayoutOptions struct {
+	// LineHeight is the height of a line.
+	// If LineHeight is 0, the height of the original face is used.
+	// The difference from the original height is divided into the spaces above and below glyphs.
+	LineHeight int
+
+	// LetterSpacing is the additional space after each glyph.
+	// LetterSpacing can be negative.
+	LetterSpacing int
+
+	// BaselineShift is the distance to move glyphs upward from the baseline.
+	// BaselineShift can be negative.
+	BaselineShift int
+}
+
+var _ font.Face = (*layoutFace)(nil)
+
+type layoutFace struct {
+	face    font.Face
+	options LayoutOptions
+}
+
+// WithLayout returns a font.Face of face with the given layout options.
+//
+// Metrics, GlyphBounds, GlyphAdvance, and Glyph of the returned face reflect the options consistently,
+// so font.Drawer and font.MeasureString work without any adjustments.
+func WithLayout(face font.Face, options LayoutOptions) font.Face {
+	return &layoutFace{
+		face:    face,
+		options: options,
+	}
+}
+
+func (l *layoutFace) Close() error {
+	return l.face.Close()
+}
+
+func (l *layoutFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	dot.Y -= fixed.I(l.options.BaselineShift)
+	dr, mask, maskp, advance, ok = l.face.Glyph(dot, r)
+	if !ok {
+		return
+	}
+	advance += fixed.I(l.options.LetterSpacing)
+	return
+}
+
+func (l *layoutFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	bounds, advance, ok = l.face.GlyphBounds(r)
+	if !ok {
+		return
+	}
+	bounds.Min.Y -= fixed.I(l.options.BaselineShift)
+	bounds.Max.Y -= fixed.I(l.options.BaselineShift)
+	advance += fixed.I(l.options.LetterSpacing)
+	return
+}
+
+func (l *layoutFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	advance, ok = l.face.GlyphAdvance(r)
+	if !ok {
+		return 0, false
+	}
+	return advance + fixed.I(l.options.LetterSpacing), true
+}
+
+func (l *layoutFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	k := l.face.Kern(r0, r1)
+	// A nonspacing mark is moved back to overlap the previous glyph.
+	// Cancel the letter spacing of the mark too.
+	if isNonspacing(l.face, r1) {
+		k -= fixed.I(l.options.LetterSpacing)
+	}
+	return k
+}
+
+func (l *layoutFace) Metrics() font.Metrics {
+	m := l.face.Metrics()
+	shift := fixed.I(l.options.BaselineShift)
+	m.Ascent += shift
+	m.Descent -= shift
+	// CapHeight and XHeight are the distances from the baseline, and 0 means they are unknown.
+	if m.CapHeight != 0 {
+		m.CapHeight += shift
+	}
+	if m.XHeight != 0 {
+		m.XHeight += shift
+	}
+	if l.options.LineHeight != 0 {
+		leading := fixed.I(l.options.LineHeight) - m.Height
+		m.Height = fixed.I(l.options.LineHeight)
+		m.Ascent += leading / 2
+		m.Descent += leading - leading/2
+	}
+	return m
+}
+
+func (l *layoutFace) glyphCoverage() *bitmap.Coverage {
+	return faceCoverage(l.face)
+}
+
+func (l *layoutFace) isNonspacing(r rune) bool {
+	return isNonspacing(l.face, r)
+}
+
+func (l *layoutFace) underlyingFaces() []font.Face {
+	return []font.Face{l.face}
+}
@@ -15,98 +15,221 @@
 package bitmapfont
 
 import (
-	"embed"
+	"context"
+	"fmt"
 	"image"
-	"io"
+	"io/fs"
 	"sync"
+	"sync/atomic"
 
-	"github.com/pierrec/lz4/v4"
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
 
 	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
 )
 
-//go:embed data/*.bin
-var data embed.FS
+// embeddedData is the embedded atlases by their names.
+// The embedded data depend on the build tags. See data_*.go.
+//
+// embeddedData is filled by the init functions in data_*.go, and the order of the init functions depends on the file names.
+// Then, embeddedData must not be used in init functions. The faces read the data only when they are used.
+var embeddedData = map[string][]byte{}
 
-const (
-	imageWidth  = 12 * 256
-	imageHeight = 16 * 256
+// readEmbeddedFile returns the embedded atlas name.
+//
+// The returned bytes are the embedded data without copying, and must not be modified.
+// The compressed pages of an atlas refer to the embedded data instead of copies on the heap.
+func readEmbeddedFile(name string) ([]byte, error) {
+	bs, ok := embeddedData[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return bs, nil
+}
 
-	dotX = 0
-	dotY = 12
-)
+const dotX = 0
 
 var _ font.Face = (*lazyFace)(nil)
 
 type lazyFace struct {
-	binFile  string
-	ea       bool
-	initOnce sync.Once
-	face     font.Face
+	// readFile reads an atlas file by its name.
+	readFile func(name string) ([]byte, error)
+
+	// atlases is the cache to share the atlases with other faces. atlases can be nil.
+	atlases *atlasCache
+
+	binFile string
+
+	// size and ea are the size and East Asian wide that the atlas must have.
+	// If size is 0, the ones in the atlas are used.
+	size int
+	ea   bool
+
+	// validatePages reports whether all the pages are validated when the atlas is read.
+	// This is for an atlas from an untrusted source, so that a broken page is reported as an error instead of a panic.
+	validatePages bool
+
+	// faces is nil until the atlas is decoded, and is reset to nil by Close.
+	faces  atomic.Pointer[bitmapFaces]
+	facesM sync.Mutex
+
+	coverageOnce sync.Once
+	coverage     *bitmap.Coverage
+	coverageErr  error
 }
 
-func newDelayedFace(binFile string, ea bool) *lazyFace {
+// bitmapFaces is the faces sharing one decoded atlas.
+type bitmapFaces struct {
+	atlas        *bitmap.BinaryImage
+	regular      *bitmap.Face
+	proportional *bitmap.Face
+}
+
+func newDelayedFace(binFile string, size int, ea bool) *lazyFace {
+	return &lazyFace{
+		readFile: readEmbeddedFile,
+		atlases:  embeddedAtlases,
+		binFile:  binFile,
+		size:     size,
+		ea:       ea,
+	}
+}
+
+func newDelayedFaceFS(fsys fs.FS, binFile string) *lazyFace {
 	return &lazyFace{
+		readFile: func(name string) ([]byte, error) {
+			return readAtlasFile(fsys, name)
+		},
 		binFile: binFile,
-		ea:      ea,
 	}
 }
 
-func (f *lazyFace) ensureInitialization() {
-	f.initOnce.Do(func() {
-		binFile, err := data.Open(f.binFile)
+func (f *lazyFace) loadCoverage() (*bitmap.Coverage, error) {
+	f.coverageOnce.Do(func() {
+		// The base atlases are not needed for the coverage.
+		a, _, err := readAtlas(f.readFile, f.binFile)
 		if err != nil {
-			panic(err)
+			f.coverageErr = err
+			return
 		}
-		defer binFile.Close()
+		f.coverage = a.coverage
+	})
+	return f.coverage, f.coverageErr
+}
 
-		s := lz4.NewReader(binFile)
+// loadFaces returns the faces, reading the atlas if needed.
+// The pages of the atlas are decoded when they are used for the first time.
+func (f *lazyFace) loadFaces() (*bitmapFaces, error) {
+	if faces := f.faces.Load(); faces != nil {
+		return faces, nil
+	}
 
-		bits, err := io.ReadAll(s)
-		if err != nil {
-			panic(err)
+	f.facesM.Lock()
+	defer f.facesM.Unlock()
+
+	if faces := f.faces.Load(); faces != nil {
+		return faces, nil
+	}
+
+	// Check the atlas before changing any state of f.
+	atlas, err := readPagedAtlas(f.readFile, f.atlases, f.binFile)
+	if err != nil {
+		return nil, err
+	}
+	h := atlas.header
+	if f.size != 0 && (h.size != f.size || h.eastAsianWide != f.ea) {
+		return nil, fmt.Errorf("bitmapfont: atlas %s doesn't match with the face: size: %d, East Asian wide: %t, want: size: %d, East Asian wide: %t", f.binFile, h.size, h.eastAsianWide, f.size, f.ea)
+	}
+	if f.validatePages {
+		if err := atlas.validatePages(); err != nil {
+			return nil, err
 		}
+	}
+
+	img := bitmap.NewPagedBinaryImage(h.cellWidth*h.runesPerPage, h.cellHeight*len(atlas.pages), h.cellHeight, atlas.decodePage)
+	face, err := bitmap.NewFace(img, atlas.coverage, &bitmap.FaceOptions{
+		CellWidth:    h.cellWidth,
+		CellHeight:   h.cellHeight,
+		DotX:         fixed.I(dotX),
+		DotY:         fixed.I(h.dotY),
+		EastAsiaWide: h.eastAsianWide,
+
+		CapHeight:  h.capHeight,
+		XHeight:    h.xHeight,
+		CaretSlope: image.Pt(h.caretSlopeRun, h.caretSlopeRise),
+
+		ProportionalGlyphs: atlas.proportionalGlyphs,
+		ProportionalKerns:  atlas.proportionalKerns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bitmapfont: invalid atlas %s: %w", f.binFile, err)
+	}
 
-		f.face = bitmap.NewFace(bitmap.NewBinaryImage(bits, imageWidth, imageHeight), fixed.I(dotX), fixed.I(dotY), f.ea)
+	f.coverageOnce.Do(func() {
+		f.coverage = atlas.coverage
 	})
+	faces := &bitmapFaces{
+		atlas:        img,
+		regular:      face,
+		proportional: face.Proportional(),
+	}
+	f.faces.Store(faces)
+	return faces, nil
 }
 
-func (f *lazyFace) Close() error {
-	// Checking whether f.face is nil ro not is not concurrent-safe.
-	// However, this is risky only when Close and other methods are called concurrently.
-	// Such a situation is not realistic.
-	if f.face == nil {
-		return nil
+// bitmapFaces returns the faces, reading the atlas if needed.
+// bitmapFaces panics if the data is broken.
+func (f *lazyFace) bitmapFaces() *bitmapFaces {
+	faces, err := f.loadFaces()
+	if err != nil {
+		panic(err)
 	}
-	if err := f.face.Close(); err != nil {
+	return faces
+}
+
+// preload reads the atlas and decodes all the pages.
+func (f *lazyFace) preload(ctx context.Context) error {
+	faces, err := f.loadFaces()
+	if err != nil {
 		return err
 	}
+	return faces.atlas.LoadPages(ctx)
+}
+
+// Close releases the decoded atlas.
+// The atlas is decoded again when the face is used after Close.
+// The atlas is kept while other faces use it, e.g., as their base.
+func (f *lazyFace) Close() error {
+	f.facesM.Lock()
+	defer f.facesM.Unlock()
+	f.faces.Store(nil)
 	return nil
 }
 
 func (f *lazyFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
-	f.ensureInitialization()
-	return f.face.Glyph(dot, r)
+	return f.bitmapFaces().regular.Glyph(dot, r)
 }
 
 func (f *lazyFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
-	f.ensureInitialization()
-	return f.face.GlyphBounds(r)
+	return f.bitmapFaces().regular.GlyphBounds(r)
 }
 
 func (f *lazyFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
-	f.ensureInitialization()
-	return f.face.GlyphAdvance(r)
+	return f.bitmapFaces().regular.GlyphAdvance(r)
 }
 
 func (f *lazyFace) Kern(r0, r1 rune) fixed.Int26_6 {
-	f.ensureInitialization()
-	return f.face.Kern(r0, r1)
+	return f.bitmapFaces().regular.Kern(r0, r1)
 }
 
 func (f *lazyFace) Metrics() font.Metrics {
-	f.ensureInitialization()
-	return f.face.Metrics()
+	return f.bitmapFaces().regular.Metrics()
+}
+
+func (f *lazyFace) glyphCoverage() *bitmap.Coverage {
+	c, err := f.loadCoverage()
+	if err != nil {
+		panic(err)
+	}
+	return c
 }
@@ -0,0 +1,74 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	"golang.org/x/image/font"
+)
+
+// LoadFace returns a font.Face of an atlas read from r.
+//
+// The atlas is a .bin file generated by the generator in _gen.
+// LoadFace reads all the data from r, and validates it including all the glyph images.
+// The glyph images are decoded lazily in the same way as Face.
+// The atlas must not refer to a base atlas, i.e., it must be generated without the -base flag.
+// Use LoadFaceFS for an atlas with a base.
+//
+// LoadFace returns an error if the data is broken or too large.
+func LoadFace(r io.Reader) (font.Face, error) {
+	const name = "face.bin"
+	bs, err := readAtlasData(r, name)
+	if err != nil {
+		return nil, err
+	}
+	return loadFace(func(n string) ([]byte, error) {
+		if n != name {
+			return nil, fmt.Errorf("bitmapfont: LoadFace: the atlas refers to the base atlas %s: use LoadFaceFS instead", path.Base(n))
+		}
+		return bs, nil
+	}, name)
+}
+
+// LoadFaceFS returns a font.Face of an atlas file name in fsys.
+//
+// The atlas is a .bin file generated by the generator in _gen.
+// If the atlas refers to a base atlas, the base atlas is also read from fsys.
+// LoadFaceFS reads the atlas and validates it including all the glyph images.
+// The glyph images are decoded lazily in the same way as Face.
+// After Close is called, the atlas is read from fsys again when the face is used.
+//
+// LoadFaceFS returns an error if the data is broken or too large.
+func LoadFaceFS(fsys fs.FS, name string) (font.Face, error) {
+	return loadFace(func(name string) ([]byte, error) {
+		return readAtlasFile(fsys, name)
+	}, name)
+}
+
+func loadFace(readFile func(name string) ([]byte, error), name string) (font.Face, error) {
+	f := &lazyFace{
+		readFile:      readFile,
+		binFile:       name,
+		validatePages: true,
+	}
+	if _, err := f.loadFaces(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
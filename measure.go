@@ -0,0 +1,70 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+type measureFacer interface {
+	measureFace() *bitmap.Face
+}
+
+// measureFace returns the bitmap face to measure strings for face, or nil if face is not supported.
+func measureFace(face font.Face) *bitmap.Face {
+	f, ok := face.(measureFacer)
+	if !ok {
+		return nil
+	}
+	return f.measureFace()
+}
+
+// MeasureString returns how far dot would advance by drawing s with face.
+//
+// The result is the same as font.MeasureString.
+// For a font.Face of this package like Face, MeasureString is faster than font.MeasureString
+// as MeasureString doesn't call the font.Face methods for each rune.
+func MeasureString(face font.Face, s string) fixed.Int26_6 {
+	if f := measureFace(face); f != nil {
+		return f.MeasureString(s)
+	}
+	return font.MeasureString(face, s)
+}
+
+// MeasureBytes returns how far dot would advance by drawing b with face.
+//
+// The result is the same as font.MeasureBytes.
+// See also MeasureString.
+func MeasureBytes(face font.Face, b []byte) fixed.Int26_6 {
+	if f := measureFace(face); f != nil {
+		return f.MeasureBytes(b)
+	}
+	return font.MeasureBytes(face, b)
+}
+
+func (f *lazyFace) measureFace() *bitmap.Face {
+	return f.bitmapFaces().regular
+}
+
+func (p *proportionalFace) measureFace() *bitmap.Face {
+	return p.face.bitmapFaces().proportional
+}
+
+func (t *tcFace) measureFace() *bitmap.Face {
+	return measureFace(t.face)
+}
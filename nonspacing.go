@@ -0,0 +1,45 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"golang.org/x/image/font"
+)
+
+type nonspacingFace interface {
+	isNonspacing(r rune) bool
+}
+
+// isNonspacing reports whether r is a nonspacing mark that face's Kern moves back to overlap the previous glyph.
+// isNonspacing returns false if face is not a font.Face of this package.
+func isNonspacing(face font.Face, r rune) bool {
+	f, ok := face.(nonspacingFace)
+	if !ok {
+		return false
+	}
+	return f.isNonspacing(r)
+}
+
+func (f *lazyFace) isNonspacing(r rune) bool {
+	return f.bitmapFaces().regular.IsNonspacing(r)
+}
+
+func (p *proportionalFace) isNonspacing(r rune) bool {
+	return p.face.bitmapFaces().proportional.IsNonspacing(r)
+}
+
+func (t *tcFace) isNonspacing(r rune) bool {
+	return isNonspacing(t.face, r)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+// obliqueSlope is the number of rows per 1px shift of the oblique style.
+const obliqueSlope = 4
+
+var _ font.Face = (*obliqueFace)(nil)
+
+type obliqueFace struct {
+	face  font.Face
+	cache glyphCache
+}
+
+// NewObliqueFace returns a font.Face of the synthetic oblique style of face.
+//
+// Each glyph is sheared row by row relative to the baseline: every 4 rows above the baseline are shifted by 1px more to the right,
+// and the rows below the baseline are shifted to the left in the same way.
+// The advances are not changed.
+// The glyph masks are cached, and Glyph doesn't allocate memory for a rune that has already been rendered.
+func NewObliqueFace(face font.Face) font.Face {
+	o := &obliqueFace{
+		face: face,
+	}
+	o.cache = glyphCache{
+		face:     face,
+		generate: o.generateGlyph,
+	}
+	return o
+}
+
+// obliqueShift returns the horizontal shift for the row at y, where the baseline is at y = 0.
+func obliqueShift(y int) int {
+	// The row at y = -1 is the lowest row above the baseline.
+	h := -y - 1
+	if h >= 0 {
+		return h / obliqueSlope
+	}
+	return -((-h + obliqueSlope - 1) / obliqueSlope)
+}
+
+func (o *obliqueFace) generateGlyph(dr image.Rectangle, mask image.Image, srcMask image.Rectangle, advance fixed.Int26_6) cachedGlyph {
+	shifts := make([]int, dr.Dy())
+	for j := range shifts {
+		shifts[j] = obliqueShift(dr.Min.Y + j)
+	}
+	m := bitmap.ShiftRows(mask, srcMask, shifts)
+	return cachedGlyph{
+		dr:      image.Rect(dr.Min.X+m.Rect.Min.X, dr.Min.Y, dr.Min.X+m.Rect.Max.X, dr.Max.Y),
+		mask:    m,
+		advance: advance,
+		ok:      true,
+	}
+}
+
+func (o *obliqueFace) Close() error {
+	o.cache.clear()
+	return o.face.Close()
+}
+
+func (o *obliqueFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	return o.cache.get(r).glyph(dot)
+}
+
+func (o *obliqueFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	bounds, advance, ok = o.face.GlyphBounds(r)
+	if !ok {
+		return
+	}
+	// A glyph without ink like a space has nothing to shift.
+	if bounds.Empty() {
+		return
+	}
+	// The top row is shifted most to the right, and the bottom row is shifted most to the left.
+	bounds.Min.X += fixed.I(obliqueShift(bounds.Max.Y.Ceil() - 1))
+	bounds.Max.X += fixed.I(obliqueShift(bounds.Min.Y.Floor()))
+	return
+}
+
+func (o *obliqueFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	return o.face.GlyphAdvance(r)
+}
+
+func (o *obliqueFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return o.face.Kern(r0, r1)
+}
+
+func (o *obliqueFace) Metrics() font.Metrics {
+	m := o.face.Metrics()
+	m.CaretSlope = image.Pt(1, obliqueSlope)
+	return m
+}
+
+func (o *obliqueFace) glyphCoverage() *bitmap.Coverage {
+	return faceCoverage(o.face)
+}
+
+func (o *obliqueFace) isNonspacing(r rune) bool {
+	return isNonspacing(o.face, r)
+}
+
+func (o *obliqueFace) underlyingFaces() []font.Face {
+	return []font.Face{o.face}
+}
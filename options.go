@@ -0,0 +1,108 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/text/language"
+)
+
+// Options represents the options for NewFace.
+type Options struct {
+	// Language is the language of the text.
+	// Language selects the preferred glyphs, e.g., zh-TW selects traditional Chinese characters.
+	// If Language doesn't match any supported languages, Japanese is used.
+	Language language.Tag
+
+	// EastAsianWide specifies whether the characters that have East Asian ambiguous widths are wide.
+	EastAsianWide bool
+
+	// Size is the glyph size in pixels, 10 or 12.
+	// If Size is 0, 12 is used.
+	//
+	// For 10px, only the Japanese glyphs are available and Language is ignored.
+	Size int
+
+	// Scale is the integer scale of the glyphs.
+	// If Scale is 0, 1 is used.
+	Scale int
+}
+
+var languageMatcher = language.NewMatcher([]language.Tag{
+	// The first one is the default.
+	language.Japanese,
+	language.SimplifiedChinese,
+	language.TraditionalChinese,
+	language.Korean,
+})
+
+// NewFace returns a font.Face for the given options.
+//
+// The returned face shares the glyph data with the corresponding face like Face or FaceTCEA.
+// NewFace returns the same face for the same options, so the scaled glyphs are cached across the calls.
+//
+// NewFace panics if the options are invalid.
+func NewFace(options Options) font.Face {
+	face := faceForOptions(options)
+	switch {
+	case options.Scale == 0:
+		return face
+	case options.Scale < 0:
+		panic(fmt.Sprintf("bitmapfont: NewFace: scale must be positive: %d", options.Scale))
+	default:
+		key := scaledFaceKey{face: face, scale: options.Scale}
+		if f, ok := scaledFaces.Load(key); ok {
+			return f.(font.Face)
+		}
+		f, _ := scaledFaces.LoadOrStore(key, NewScaledFace(face, options.Scale))
+		return f.(font.Face)
+	}
+}
+
+type scaledFaceKey struct {
+	face  font.Face
+	scale int
+}
+
+// scaledFaces is the scaled faces returned by NewFace by scaledFaceKey.
+var scaledFaces sync.Map
+
+func faceForOptions(options Options) font.Face {
+	switch options.Size {
+	case 0, 12:
+	case 10:
+		if options.EastAsianWide {
+			return Face10EA
+		}
+		return Face10
+	default:
+		panic(fmt.Sprintf("bitmapfont: NewFace: unsupported size: %d", options.Size))
+	}
+
+	_, index, _ := languageMatcher.Match(options.Language)
+	faces := [][2]font.Face{
+		{Face, FaceEA},
+		{FaceSC, FaceSCEA},
+		{FaceTC, FaceTCEA},
+		{FaceKO, FaceKOEA},
+	}
+	if options.EastAsianWide {
+		return faces[index][1]
+	}
+	return faces[index][0]
+}
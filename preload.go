@@ -0,0 +1,119 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"sync"
+
+	"golang.org/x/image/font"
+)
+
+// wrapperFace is a face of this package based on other faces, like the faces returned by NewScaledFace.
+type wrapperFace interface {
+	underlyingFaces() []font.Face
+}
+
+// appendDataFaces appends the faces that load the data for face to faces.
+// The appended faces are *lazyFace or *unavailableFace.
+func appendDataFaces(faces []font.Face, face font.Face) []font.Face {
+	switch f := face.(type) {
+	case *lazyFace, *unavailableFace:
+		if !slices.Contains(faces, face) {
+			faces = append(faces, f)
+		}
+	case wrapperFace:
+		for _, f := range f.underlyingFaces() {
+			faces = appendDataFaces(faces, f)
+		}
+	}
+	return faces
+}
+
+// Preload loads and decodes the glyph data of faces in advance.
+//
+// The glyph data of a face of this package is decoded on demand when the face is used.
+// Preload lets you pay the cost at a predictable time, e.g., on a loading screen or in a background goroutine.
+//
+// faces can include the faces returned by the functions of this package like NewScaledFace.
+// Other font.Face values are ignored.
+//
+// Preload decodes the faces in parallel.
+// To decode the faces one by one, call Preload for each face.
+//
+// Preload returns the first error in the order of faces, or ctx.Err() if ctx is done before all the faces are decoded.
+// The glyph data decoded so far is kept even if Preload returns an error.
+// Preload can be called concurrently with the methods of the faces.
+func Preload(ctx context.Context, faces ...font.Face) error {
+	var dataFaces []font.Face
+	for _, face := range faces {
+		dataFaces = appendDataFaces(dataFaces, face)
+	}
+
+	errs := make([]error, len(dataFaces))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, face := range dataFaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() {
+				<-sem
+			}()
+			switch f := face.(type) {
+			case *lazyFace:
+				errs[i] = f.preload(ctx)
+			case *unavailableFace:
+				errs[i] = f.err()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Err returns the error of initializing face, or nil if face is ready to use.
+//
+// A face of this package panics when its data cannot be read, e.g., when the data is excluded by the build tags.
+// Err reads the data in the same way, but returns the error instead of panicking.
+// Unlike Preload, Err doesn't decode the glyph images.
+//
+// face can be a face returned by the functions of this package like NewScaledFace.
+// Err returns nil for other font.Face values.
+func Err(face font.Face) error {
+	for _, face := range appendDataFaces(nil, face) {
+		switch f := face.(type) {
+		case *lazyFace:
+			if _, err := f.loadFaces(); err != nil {
+				return err
+			}
+		case *unavailableFace:
+			return f.err()
+		}
+	}
+	return nil
+}
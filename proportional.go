@@ -0,0 +1,96 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"fmt"
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+type proportionalFacer interface {
+	proportionalFace() font.Face
+}
+
+// NewProportionalFace returns a face where Latin, Greek, and Cyrillic glyphs have proportional advances.
+//
+// The advances are based on the ink of the glyphs, and some pairs like "Te" are kerned.
+// The other glyphs like CJK glyphs keep their fixed widths.
+//
+// face must be one of the faces of this package, such as Face or FaceTC.
+// The returned face shares the glyph data with face.
+//
+// NewProportionalFace panics if face is not supported.
+func NewProportionalFace(face font.Face) font.Face {
+	f, ok := face.(proportionalFacer)
+	if !ok {
+		panic(fmt.Sprintf("bitmapfont: NewProportionalFace: unsupported face: %T", face))
+	}
+	return f.proportionalFace()
+}
+
+func (f *lazyFace) proportionalFace() font.Face {
+	return &proportionalFace{face: f}
+}
+
+func (t *tcFace) proportionalFace() font.Face {
+	return &tcFace{face: NewProportionalFace(t.face)}
+}
+
+var _ font.Face = (*proportionalFace)(nil)
+
+type proportionalFace struct {
+	face *lazyFace
+}
+
+func (p *proportionalFace) proportionalFace() font.Face {
+	return p
+}
+
+func (p *proportionalFace) Close() error {
+	return p.face.Close()
+}
+
+func (p *proportionalFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	return p.face.bitmapFaces().proportional.Glyph(dot, r)
+}
+
+func (p *proportionalFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	return p.face.bitmapFaces().proportional.GlyphBounds(r)
+}
+
+func (p *proportionalFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	return p.face.bitmapFaces().proportional.GlyphAdvance(r)
+}
+
+func (p *proportionalFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return p.face.bitmapFaces().proportional.Kern(r0, r1)
+}
+
+func (p *proportionalFace) Metrics() font.Metrics {
+	return p.face.bitmapFaces().proportional.Metrics()
+}
+
+func (p *proportionalFace) glyphCoverage() *bitmap.Coverage {
+	return p.face.glyphCoverage()
+}
+
+func (p *proportionalFace) underlyingFaces() []font.Face {
+	return []font.Face{p.face}
+}
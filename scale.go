@@ -0,0 +1,123 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+var _ font.Face = (*scaledFace)(nil)
+
+type scaledFace struct {
+	face  font.Face
+	scale int
+	cache glyphCache
+}
+
+// NewScaledFace returns a font.Face that scales the glyphs of face by the integer factor scale.
+//
+// The glyphs are scaled with the nearest-neighbor filter, so the glyphs of this package are kept pixel-exact.
+// The scaled glyph masks are cached, and Glyph doesn't allocate memory for a rune that has already been rendered.
+//
+// face is assumed to render glyphs at integer positions, like the faces of this package do.
+//
+// NewScaledFace panics if scale is not positive.
+// If scale is 1, NewScaledFace returns face as it is.
+func NewScaledFace(face font.Face, scale int) font.Face {
+	if scale <= 0 {
+		panic("bitmapfont: scale must be positive")
+	}
+	if scale == 1 {
+		return face
+	}
+	s := &scaledFace{
+		face:  face,
+		scale: scale,
+	}
+	s.cache = glyphCache{
+		face:     face,
+		generate: s.generateGlyph,
+	}
+	return s
+}
+
+func (s *scaledFace) generateGlyph(dr image.Rectangle, mask image.Image, srcMask image.Rectangle, advance fixed.Int26_6) cachedGlyph {
+	return cachedGlyph{
+		dr:      image.Rect(dr.Min.X*s.scale, dr.Min.Y*s.scale, dr.Max.X*s.scale, dr.Max.Y*s.scale),
+		mask:    bitmap.Scale(mask, srcMask, s.scale),
+		advance: advance * fixed.Int26_6(s.scale),
+		ok:      true,
+	}
+}
+
+func (s *scaledFace) Close() error {
+	s.cache.clear()
+	return s.face.Close()
+}
+
+func (s *scaledFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	return s.cache.get(r).glyph(dot)
+}
+
+func (s *scaledFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	bounds, advance, ok = s.face.GlyphBounds(r)
+	if !ok {
+		return
+	}
+	k := fixed.Int26_6(s.scale)
+	bounds.Min.X *= k
+	bounds.Min.Y *= k
+	bounds.Max.X *= k
+	bounds.Max.Y *= k
+	advance *= k
+	return
+}
+
+func (s *scaledFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	advance, ok = s.face.GlyphAdvance(r)
+	return advance * fixed.Int26_6(s.scale), ok
+}
+
+func (s *scaledFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return s.face.Kern(r0, r1) * fixed.Int26_6(s.scale)
+}
+
+func (s *scaledFace) Metrics() font.Metrics {
+	m := s.face.Metrics()
+	k := fixed.Int26_6(s.scale)
+	m.Height *= k
+	m.Ascent *= k
+	m.Descent *= k
+	m.XHeight *= k
+	m.CapHeight *= k
+	return m
+}
+
+func (s *scaledFace) glyphCoverage() *bitmap.Coverage {
+	return faceCoverage(s.face)
+}
+
+func (s *scaledFace) isNonspacing(r rune) bool {
+	return isNonspacing(s.face, r)
+}
+
+func (s *scaledFace) underlyingFaces() []font.Face {
+	return []font.Face{s.face}
+}
@@ -0,0 +1,33 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"io/fs"
+
+	"golang.org/x/image/font"
+)
+
+// NewSubsetFace returns a font.Face of a subset atlas generated by the generator with the -subset flag.
+//
+// binFile is the name of the atlas file in fsys.
+// The size and whether the face is East Asian wide are read from the atlas.
+// If the atlas is broken, the face panics when it is used, and Err returns the error.
+//
+// The atlas is decoded lazily in the same way as Face.
+// The generator also outputs a Go file that calls NewSubsetFace, so usually you don't have to call this directly.
+func NewSubsetFace(fsys fs.FS, binFile string) font.Face {
+	return newDelayedFaceFS(fsys, binFile)
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitmapfont
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/bitmapfont/v4/internal/bitmap"
+)
+
+// newEmbeddedFace returns a face for the embedded data binFile.
+//
+// If available is false, newEmbeddedFace returns a face that panics when it is used.
+// available must be decided by the build tags, not by whether binFile is embedded, as binFile can be embedded as the base of other faces.
+// name is the name of the face and tags are the build tags that exclude the face, which are used for the panic message.
+func newEmbeddedFace(name string, binFile string, size int, ea bool, available bool, tags ...string) font.Face {
+	if !available {
+		return &unavailableFace{
+			name: name,
+			tags: tags,
+		}
+	}
+	return newDelayedFace(binFile, size, ea)
+}
+
+var _ font.Face = (*unavailableFace)(nil)
+
+// unavailableFace is a face whose data is excluded by the build tags.
+type unavailableFace struct {
+	name string
+	tags []string
+}
+
+func (u *unavailableFace) err() error {
+	return fmt.Errorf("bitmapfont: %s is not available as its data is excluded by the build tag %s", u.name, strings.Join(u.tags, " or "))
+}
+
+func (u *unavailableFace) Close() error {
+	return u.err()
+}
+
+func (u *unavailableFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	panic(u.err())
+}
+
+func (u *unavailableFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	panic(u.err())
+}
+
+func (u *unavailableFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	panic(u.err())
+}
+
+func (u *unavailableFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	panic(u.err())
+}
+
+func (u *unavailableFace) Metrics() font.Metrics {
+	panic(u.err())
+}
+
+// glyphCoverage panics so that RangeTable, Glyphs, and HasGlyph behave in the same way as drawing.
+func (u *unavailableFace) glyphCoverage() *bitmap.Coverage {
+	panic(u.err())
+}
+
+func (u *unavailableFace) proportionalFace() font.Face {
+	return u
+}